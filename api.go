@@ -0,0 +1,26 @@
+package projectx
+
+import "time"
+
+// TradingAPI is the set of gateway operations provided by Client. Strategy code
+// can depend on it instead of *Client so tests can substitute a fake such as
+// projectxtest.FakeClient.
+type TradingAPI interface {
+	Login(username, apiKey string) error
+	GetAccounts(onlyActive bool) ([]Account, error)
+	GetContracts(live bool, searchText string) ([]Contract, error)
+	GetContractByID(contractID string) (*Contract, error)
+	GetAvailableContracts(live bool) ([]Contract, error)
+	PlaceOrder(order OrderRequest) (*OrderResponse, error)
+	CancelOrder(accountId, orderId int) error
+	ModifyOrder(accountId, orderId int, size *int, limitPrice, stopPrice, trailPrice *float64) error
+	GetOpenPositions(accountId int) ([]OpenPosition, error)
+	ClosePosition(accountId int, contractId string, size int) error
+	PartialClosePosition(accountId int, contractId string, size int) error
+	GetHistoricalBars(req HistoryRequest) ([]HistoryBar, error)
+	SearchOrders(req OrderSearchRequest) ([]OrderInfo, error)
+	SearchOpenOrders(accountId int) ([]OrderInfo, error)
+	SearchTrades(accountId int, start, end *time.Time) ([]Trade, error)
+}
+
+var _ TradingAPI = (*Client)(nil)
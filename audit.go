@@ -0,0 +1,73 @@
+package projectx
+
+import "time"
+
+// OrderAuditAction is the kind of order submission recorded by an OrderAuditSink.
+type OrderAuditAction int
+
+const (
+	OrderAuditPlace OrderAuditAction = iota + 1
+	OrderAuditModify
+	OrderAuditCancel
+	OrderAuditClose        // ClosePosition
+	OrderAuditPartialClose // PartialClosePosition
+)
+
+var OrderAuditActionName = map[OrderAuditAction]string{
+	OrderAuditPlace:        "Place",
+	OrderAuditModify:       "Modify",
+	OrderAuditCancel:       "Cancel",
+	OrderAuditClose:        "Close",
+	OrderAuditPartialClose: "PartialClose",
+}
+
+func (a OrderAuditAction) String() string {
+	return OrderAuditActionName[a]
+}
+
+// OrderAuditEntry records one order submission and its outcome.
+type OrderAuditEntry struct {
+	Time       time.Time // When the submission was sent
+	Action     OrderAuditAction
+	AccountID  int
+	ContractID string         // Known for placements and position closes only
+	OrderID    int            // Resulting order for placements, target order otherwise; 0 if none
+	Request    *OrderRequest  // Placement request
+	Response   *OrderResponse // Placement response, nil when the request itself failed
+	Size       *int           // Modification fields as sent; Size also sizes position closes
+	LimitPrice *float64
+	StopPrice  *float64
+	TrailPrice *float64
+	Err        error // Submission error, including gateway rejections
+	DryRun     bool  // Set when the submission was skipped in dry-run mode
+}
+
+// OrderAuditSink receives an entry for every PlaceOrder, ModifyOrder, CancelOrder,
+// ClosePosition and PartialClosePosition call, after the gateway has answered, for a
+// structured order audit trail. Unlike the Logger it records every submission,
+// successful or not. RecordOrder is called synchronously on the calling goroutine, so
+// it should be quick and safe for concurrent use.
+type OrderAuditSink interface {
+	RecordOrder(entry OrderAuditEntry)
+}
+
+// OrderAuditFunc adapts a function to an OrderAuditSink.
+type OrderAuditFunc func(entry OrderAuditEntry)
+
+func (f OrderAuditFunc) RecordOrder(entry OrderAuditEntry) {
+	f(entry)
+}
+
+// WithOrderAudit sends every order submission to sink. Nil disables auditing.
+func WithOrderAudit(sink OrderAuditSink) ClientOption {
+	return func(c *Client) {
+		c.audit = sink
+	}
+}
+
+// recordOrder passes entry to the audit sink, if any.
+func (c *Client) recordOrder(entry OrderAuditEntry) {
+	if c.audit != nil {
+		c.audit.RecordOrder(entry)
+	}
+}
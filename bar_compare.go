@@ -0,0 +1,95 @@
+package projectx
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// BarMismatchKind classifies a discrepancy found by CompareBars.
+type BarMismatchKind int
+
+const (
+	BarMissing BarMismatchKind = iota + 1 // Bar in a with no bar at the same time in b
+	BarExtra                              // Bar in b with no bar at the same time in a
+	BarDiffers                            // Bars at the same time whose values differ
+)
+
+var BarMismatchKindName = map[BarMismatchKind]string{
+	BarMissing: "Missing",
+	BarExtra:   "Extra",
+	BarDiffers: "Differs",
+}
+
+func (k BarMismatchKind) String() string {
+	return BarMismatchKindName[k]
+}
+
+// BarMismatch is one discrepancy between two bar series.
+type BarMismatch struct {
+	Time   time.Time
+	Kind   BarMismatchKind
+	Fields []string    // Differing fields for BarDiffers: "open", "high", "low", "close", "volume"
+	A      *HistoryBar // Bar from a, nil for BarExtra
+	B      *HistoryBar // Bar from b, nil for BarMissing
+}
+
+// CompareBars aligns two bar series by timestamp and reports their discrepancies
+// oldest first, e.g. with a as the broker's history and b as bars built by a
+// MarketDataManager. Bars match when their times are the same instant, whatever the
+// location. Prices and volume differ when they are more than tolerance apart, so a
+// tolerance below one requires equal volumes. Bars may be in either order; of bars
+// sharing a timestamp within one series, the last is compared.
+func CompareBars(a, b []HistoryBar, tolerance float64) []BarMismatch {
+	byTime := func(bars []HistoryBar) map[int64]*HistoryBar {
+		m := make(map[int64]*HistoryBar, len(bars))
+		for i := range bars {
+			m[bars[i].Time.UnixNano()] = &bars[i]
+		}
+		return m
+	}
+	inA, inB := byTime(a), byTime(b)
+
+	var mismatches []BarMismatch
+	for t, barA := range inA {
+		barB, ok := inB[t]
+		if !ok {
+			mismatches = append(mismatches, BarMismatch{Time: barA.Time, Kind: BarMissing, A: barA})
+			continue
+		}
+		if fields := diffBars(*barA, *barB, tolerance); len(fields) > 0 {
+			mismatches = append(mismatches, BarMismatch{Time: barA.Time, Kind: BarDiffers, Fields: fields, A: barA, B: barB})
+		}
+	}
+	for t, barB := range inB {
+		if _, ok := inA[t]; !ok {
+			mismatches = append(mismatches, BarMismatch{Time: barB.Time, Kind: BarExtra, B: barB})
+		}
+	}
+
+	sort.Slice(mismatches, func(i, j int) bool {
+		return mismatches[i].Time.Before(mismatches[j].Time)
+	})
+	return mismatches
+}
+
+// diffBars returns the names of the fields of a and b more than tolerance apart.
+func diffBars(a, b HistoryBar, tolerance float64) []string {
+	values := []struct {
+		name string
+		a, b float64
+	}{
+		{"open", a.Open, b.Open},
+		{"high", a.High, b.High},
+		{"low", a.Low, b.Low},
+		{"close", a.Close, b.Close},
+		{"volume", float64(a.Vol), float64(b.Vol)},
+	}
+	var fields []string
+	for _, v := range values {
+		if math.Abs(v.a-v.b) > tolerance {
+			fields = append(fields, v.name)
+		}
+	}
+	return fields
+}
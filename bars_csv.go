@@ -0,0 +1,79 @@
+package projectx
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+)
+
+// barsCSVHeader is the column order written by WriteBarsCSV and expected by ReadBarsCSV.
+var barsCSVHeader = []string{"time", "open", "high", "low", "close", "volume"}
+
+// WriteBarsCSV writes bars as OHLCV CSV with a header row. Times are RFC 3339 in UTC and
+// prices use the shortest representation that round-trips exactly.
+func WriteBarsCSV(w io.Writer, bars []HistoryBar) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(barsCSVHeader); err != nil {
+		return err
+	}
+
+	record := make([]string, len(barsCSVHeader))
+	for _, bar := range bars {
+		record[0] = bar.Time.UTC().Format(time.RFC3339)
+		record[1] = strconv.FormatFloat(bar.Open, 'f', -1, 64)
+		record[2] = strconv.FormatFloat(bar.High, 'f', -1, 64)
+		record[3] = strconv.FormatFloat(bar.Low, 'f', -1, 64)
+		record[4] = strconv.FormatFloat(bar.Close, 'f', -1, 64)
+		record[5] = strconv.Itoa(bar.Vol)
+		if err := cw.Write(record); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// ReadBarsCSV reads bars written by WriteBarsCSV. The header row must match.
+func ReadBarsCSV(r io.Reader) ([]HistoryBar, error) {
+	cr := csv.NewReader(r)
+	cr.FieldsPerRecord = len(barsCSVHeader)
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CSV header: %v", err)
+	}
+	for i, name := range barsCSVHeader {
+		if header[i] != name {
+			return nil, fmt.Errorf("unexpected CSV column %q, want %q", header[i], name)
+		}
+	}
+
+	var bars []HistoryBar
+	for line := 2; ; line++ {
+		record, err := cr.Read()
+		if err == io.EOF {
+			return bars, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		var bar HistoryBar
+		if bar.Time, err = time.Parse(time.RFC3339, record[0]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid time: %v", line, err)
+		}
+		prices := []*float64{&bar.Open, &bar.High, &bar.Low, &bar.Close}
+		for i, p := range prices {
+			if *p, err = strconv.ParseFloat(record[i+1], 64); err != nil {
+				return nil, fmt.Errorf("line %d: invalid %s: %v", line, barsCSVHeader[i+1], err)
+			}
+		}
+		if bar.Vol, err = strconv.Atoi(record[5]); err != nil {
+			return nil, fmt.Errorf("line %d: invalid volume: %v", line, err)
+		}
+		bars = append(bars, bar)
+	}
+}
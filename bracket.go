@@ -0,0 +1,142 @@
+package projectx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// OrderBracket is a protective order the gateway places when the entry it is attached
+// to fills. Ticks is signed relative to the fill price: negative below it, positive
+// above it.
+type OrderBracket struct {
+	Ticks int `json:"ticks"`
+	Type  int `json:"type"` // Order type of the protective order, e.g. OrderTypeStop
+}
+
+// Bracket describes the stop loss and take profit placed with PlaceBracketOrder.
+type Bracket struct {
+	StopTicks   int     // Stop loss distance from the entry in ticks, zero for none
+	TargetTicks int     // Take profit distance from the entry in ticks, zero for none
+	TickSize    float64 // Contract tick size, used to price client-side legs
+	Reference   float64 // Entry price client-side legs are priced from; zero means the entry's limit or stop price
+}
+
+// How PlaceBracketOrder places brackets, stored in Client.nativeBrackets.
+const (
+	bracketClientSide int32 = iota // Legs placed with PlaceOrderGroup, the default
+	bracketNative                  // Brackets in the order payload, see WithNativeBrackets
+	bracketRejected                // Payload brackets enabled but rejected by the gateway
+)
+
+// WithNativeBrackets sets whether PlaceBracketOrder sends brackets in the order payload
+// (true) or places them client-side with PlaceOrderGroup (false, the default). Enable
+// it only for gateways and accounts known to support payload brackets: a gateway that
+// ignores the fields accepts the entry without protection, which the client cannot
+// detect until the entry fills.
+func WithNativeBrackets(enabled bool) ClientOption {
+	return func(c *Client) {
+		if enabled {
+			c.nativeBrackets.Store(bracketNative)
+		} else {
+			c.nativeBrackets.Store(bracketClientSide)
+		}
+	}
+}
+
+// PlaceBracketOrder places the entry order protected by a stop loss and take profit.
+//
+// By default the legs are placed client-side with PlaceOrderGroup, priced from
+// bracket.Reference or the entry's limit or stop price, and the returned handle lists
+// them. With WithNativeBrackets the brackets are instead sent in the entry's payload,
+// so the gateway attaches them atomically when the entry fills; the returned handle
+// then has Native set and no LegIDs. If the gateway rejects that order, the bracket
+// is placed client-side; when the entry is then accepted, the payload fields were the
+// cause and later brackets go straight to client-side legs.
+func (c *Client) PlaceBracketOrder(entry OrderRequest, bracket Bracket) (*OrderGroupHandle, error) {
+	if bracket.StopTicks < 0 || bracket.TargetTicks < 0 {
+		return nil, fmt.Errorf("order bracket failed: tick distances must not be negative")
+	}
+
+	native := c.nativeBrackets.Load() == bracketNative
+	var rejection error
+	if native {
+		handle, err := c.placeNativeBracket(entry, bracket)
+		var apiErr *APIError
+		if !errors.As(err, &apiErr) {
+			return handle, err
+		}
+		rejection = err
+	}
+
+	group, err := bracketGroup(entry, bracket)
+	if err != nil {
+		if rejection != nil {
+			// Client-side legs cannot be priced, so the rejection stands
+			return nil, rejection
+		}
+		return nil, err
+	}
+	if rejection != nil {
+		c.logf("order with payload brackets rejected, placing client-side legs: %v", rejection)
+	}
+	handle, err := c.PlaceOrderGroup(group)
+	if native && handle != nil {
+		// The same entry was accepted without the payload brackets
+		c.logf("payload brackets rejected by the gateway, placing client-side legs from now on")
+		c.nativeBrackets.CompareAndSwap(bracketNative, bracketRejected)
+	}
+	return handle, err
+}
+
+// placeNativeBracket places entry with the brackets in its payload.
+func (c *Client) placeNativeBracket(entry OrderRequest, bracket Bracket) (*OrderGroupHandle, error) {
+	sign := entry.Side.Sign()
+	if bracket.StopTicks > 0 {
+		entry.StopLossBracket = &OrderBracket{Ticks: -sign * bracket.StopTicks, Type: OrderTypeStop}
+	}
+	if bracket.TargetTicks > 0 {
+		entry.TakeProfitBracket = &OrderBracket{Ticks: sign * bracket.TargetTicks, Type: OrderTypeLimit}
+	}
+	resp, err := c.PlaceOrder(entry)
+	if err != nil {
+		return nil, err
+	}
+	return &OrderGroupHandle{AccountID: entry.AccountID, EntryID: resp.OrderID, Native: true}, nil
+}
+
+// bracketGroup builds the client-side legs of bracket for entry.
+func bracketGroup(entry OrderRequest, bracket Bracket) (OrderGroup, error) {
+	reference := bracket.Reference
+	if reference == 0 && entry.LimitPrice != nil {
+		reference = *entry.LimitPrice
+	}
+	if reference == 0 && entry.StopPrice != nil {
+		reference = *entry.StopPrice
+	}
+	if reference == 0 || bracket.TickSize <= 0 {
+		return OrderGroup{}, fmt.Errorf("order bracket failed: client-side legs need a reference price and tick size")
+	}
+
+	stop, target := CalcProtectivePrices(reference, entry.Side, bracket.StopTicks, bracket.TargetTicks, bracket.TickSize)
+	leg := OrderRequest{
+		AccountID:  entry.AccountID,
+		ContractID: entry.ContractID,
+		Side:       entry.Side.Opposite(),
+		Size:       entry.Size,
+	}
+
+	group := OrderGroup{Entry: entry}
+	if bracket.StopTicks > 0 {
+		stopLeg := leg
+		stopLeg.Type = OrderTypeStop
+		stopLeg.StopPrice = &stop
+		group.Legs = append(group.Legs, stopLeg)
+	}
+	if bracket.TargetTicks > 0 {
+		targetLeg := leg
+		targetLeg.Type = OrderTypeLimit
+		targetLeg.LimitPrice = &target
+		group.Legs = append(group.Legs, targetLeg)
+	}
+	return group, nil
+}
@@ -0,0 +1,109 @@
+package projectx
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// orderServer accepts placements unless reject returns true for them, numbering
+// accepted orders from 1, and records every placement request.
+func orderServer(t *testing.T, reject func(OrderRequest) bool) (*httptest.Server, *[]OrderRequest) {
+	t.Helper()
+	var orders []OrderRequest
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var order OrderRequest
+		if err := json.NewDecoder(r.Body).Decode(&order); err != nil {
+			t.Errorf("decode order: %v", err)
+		}
+		orders = append(orders, order)
+		if reject(order) {
+			w.Write([]byte(`{"success":false,"errorCode":2,"errorMessage":"Order rejected"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"success":true,"errorCode":0,"orderId":%d}`, len(orders))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &orders
+}
+
+func hasPayloadBrackets(order OrderRequest) bool {
+	return order.StopLossBracket != nil || order.TakeProfitBracket != nil
+}
+
+func TestPlaceBracketOrder(t *testing.T) {
+	limit := 4700.0
+	entry := OrderRequest{AccountID: 7, ContractID: "CON.F.US.EP.H24", Type: OrderTypeLimit, Side: OrderSideBidBuy, Size: 1, LimitPrice: &limit}
+	bracket := Bracket{StopTicks: 8, TargetTicks: 16, TickSize: 0.25}
+
+	t.Run("client-side by default", func(t *testing.T) {
+		srv, orders := orderServer(t, func(OrderRequest) bool { return false })
+		handle, err := NewClient(srv.URL).PlaceBracketOrder(entry, bracket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if handle.Native || handle.EntryID != 1 || len(handle.LegIDs) != 2 {
+			t.Fatalf("handle %+v, want entry 1 with two client-side legs", handle)
+		}
+		if len(*orders) != 3 || hasPayloadBrackets((*orders)[0]) {
+			t.Fatalf("placed %+v", *orders)
+		}
+		stop, target := (*orders)[1], (*orders)[2]
+		if stop.Type != OrderTypeStop || *stop.StopPrice != 4698 || target.Type != OrderTypeLimit || *target.LimitPrice != 4704 {
+			t.Errorf("legs at stop %v and target %v, want 4698 and 4704", *stop.StopPrice, *target.LimitPrice)
+		}
+	})
+
+	t.Run("payload brackets opted in", func(t *testing.T) {
+		srv, orders := orderServer(t, func(OrderRequest) bool { return false })
+		handle, err := NewClient(srv.URL, WithNativeBrackets(true)).PlaceBracketOrder(entry, bracket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !handle.Native || len(handle.LegIDs) != 0 || len(*orders) != 1 {
+			t.Fatalf("handle %+v after %d placements, want one native order", handle, len(*orders))
+		}
+		got := (*orders)[0]
+		if *got.StopLossBracket != (OrderBracket{Ticks: -8, Type: OrderTypeStop}) || *got.TakeProfitBracket != (OrderBracket{Ticks: 16, Type: OrderTypeLimit}) {
+			t.Errorf("brackets %+v and %+v", *got.StopLossBracket, *got.TakeProfitBracket)
+		}
+	})
+
+	t.Run("payload brackets rejected", func(t *testing.T) {
+		srv, orders := orderServer(t, hasPayloadBrackets)
+		c := NewClient(srv.URL, WithNativeBrackets(true))
+		handle, err := c.PlaceBracketOrder(entry, bracket)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if handle.Native || len(handle.LegIDs) != 2 || len(*orders) != 4 {
+			t.Fatalf("handle %+v after %d placements, want client-side legs after one rejection", handle, len(*orders))
+		}
+
+		// The accepted retry shows the brackets were the cause: no more payload brackets
+		*orders = nil
+		if _, err := c.PlaceBracketOrder(entry, bracket); err != nil {
+			t.Fatal(err)
+		}
+		if len(*orders) != 3 || hasPayloadBrackets((*orders)[0]) {
+			t.Errorf("placed %+v, want client-side legs only", *orders)
+		}
+	})
+
+	t.Run("order rejected", func(t *testing.T) {
+		srv, orders := orderServer(t, func(OrderRequest) bool { return true })
+		c := NewClient(srv.URL, WithNativeBrackets(true))
+		if _, err := c.PlaceBracketOrder(entry, bracket); err == nil {
+			t.Fatal("rejected order succeeded")
+		}
+
+		// The order itself was refused, so payload brackets are still used
+		*orders = nil
+		c.PlaceBracketOrder(entry, bracket)
+		if len(*orders) == 0 || !hasPayloadBrackets((*orders)[0]) {
+			t.Errorf("placed %+v, want payload brackets tried first", *orders)
+		}
+	})
+}
@@ -2,44 +2,259 @@ package projectx
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"sync"
+	"sync/atomic"
+	"time"
 )
 
-var ErrUnauthorized = errors.New("unauthorized")
+var (
+	ErrUnauthorized = errors.New("unauthorized")
+
+	// ErrUnreachable is returned by Ping when the gateway could not be reached.
+	ErrUnreachable = errors.New("gateway unreachable")
+
+	// ErrNotFound is returned when a looked-up entity such as an order does not exist.
+	ErrNotFound = errors.New("not found")
+
+	// ErrTokenExpired is returned without contacting the gateway when the token expiry
+	// check is enabled, the token has expired and no auth function is set.
+	ErrTokenExpired = errors.New("token expired")
+
+	// ErrTruncatedResponse is returned when the connection drops before the
+	// response body could be fully decoded. It is safe to retry.
+	ErrTruncatedResponse = errors.New("truncated response")
+
+	// ErrUnknownContract is returned by SignalRClient subscriptions when contract
+	// validation is enabled and the gateway does not know the contract ID.
+	ErrUnknownContract = errors.New("unknown contract")
+)
+
+// IsRetryable reports whether err is a transient transport error that can be retried.
+func IsRetryable(err error) bool {
+	return errors.Is(err, ErrTruncatedResponse)
+}
 
 type Client struct {
-	BaseURL   string
-	Token     string
+	BaseURL string
+
+	// Token is the JWT sent with authenticated requests.
+	//
+	// Deprecated: Use GetToken and SetToken. The client replaces the token when it
+	// re-authenticates, so reading or writing the field while requests are in flight
+	// is a data race. Setting it before the client is first used remains safe.
+	Token string
+
 	UserAgent string
 
-	authFunc func() error
+	tokenMutex     sync.RWMutex  // Protects Token and refreshing
+	refreshing     *tokenRefresh // Refresh in progress, nil when none
+	authFunc       func() error
+	tokenListeners []func(token string)
+
+	retryPredicate   RetryPredicate
+	retryMaxAttempts int
+	retryDelay       time.Duration
+
+	httpClient *http.Client
+	timeout    time.Duration
+	limiter    *rateLimiter
+	inflight   concurrencyLimiter
+	logger     Logger
+
+	expiryCheck bool
+	expirySkew  time.Duration
+
+	userHub   *UserHubClient
+	contracts contractCache
+	margins   MarginSchedule
+	audit     OrderAuditSink
+	schedules TradingSchedules
+
+	nativeBrackets atomic.Int32 // How PlaceBracketOrder places brackets, see WithNativeBrackets
+
+	dryRun        bool         // Skip mutating calls, see WithDryRun
+	dryRunOrderID atomic.Int64 // Last synthetic order ID handed out in dry-run mode
+}
+
+// Logger receives diagnostic messages from the Client. *log.Logger satisfies it.
+type Logger interface {
+	Printf(format string, v ...any)
 }
 
-func NewClient(baseURL string) *Client {
-	return &Client{
+// ClientOption configures a Client created by NewClient.
+type ClientOption func(*Client)
+
+// WithHTTPClient sets the HTTP client used for requests, e.g. one with a custom
+// transport or proxy. The default is http.DefaultClient.
+func WithHTTPClient(httpClient *http.Client) ClientOption {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout bounds each request attempt, including reading the response body.
+// Zero, the default, means no timeout beyond that of the HTTP client.
+func WithTimeout(timeout time.Duration) ClientOption {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithRateLimit allows at most n requests per interval, e.g. 50 per 30 seconds.
+// Requests over the limit wait for capacity rather than fail. A non-positive n or
+// interval disables rate limiting.
+func WithRateLimit(n int, per time.Duration) ClientOption {
+	return func(c *Client) {
+		c.limiter = nil
+		if n > 0 && per > 0 {
+			c.limiter = newRateLimiter(n, per)
+		}
+	}
+}
+
+// WithMaxConcurrency allows at most n requests in flight at once, across goroutines,
+// since the gateway may also limit concurrent connections. Further requests wait for a
+// slot, or fail when their context ends first. A slot is held for each attempt until
+// its response has been read, not across retry delays. A non-positive n means
+// unlimited.
+func WithMaxConcurrency(n int) ClientOption {
+	return func(c *Client) {
+		c.inflight = nil
+		if n > 0 {
+			c.inflight = newConcurrencyLimiter(n)
+		}
+	}
+}
+
+// WithLogger logs retries, token refreshes and failed requests to logger.
+func WithLogger(logger Logger) ClientOption {
+	return func(c *Client) {
+		c.logger = logger
+	}
+}
+
+// WithTokenExpiryCheck checks the JWT expiry before each authenticated request. A token
+// that expires within skew is refreshed first through the WithAutoRetry auth function;
+// without one the request fails with ErrTokenExpired instead of making a call doomed to
+// return 401. Off by default for callers who manage tokens themselves.
+func WithTokenExpiryCheck(skew time.Duration) ClientOption {
+	return func(c *Client) {
+		c.expiryCheck = true
+		c.expirySkew = skew
+	}
+}
+
+// WithUserAgent overrides the User-Agent header sent with every request.
+func WithUserAgent(userAgent string) ClientOption {
+	return func(c *Client) {
+		c.UserAgent = userAgent
+	}
+}
+
+// RetryPredicate decides whether a request attempt should be retried. status is the
+// HTTP status code, or 0 when the request failed in transport (including a truncated
+// body), body is the raw response body, and attempt starts at 1.
+type RetryPredicate func(status int, body []byte, attempt int) bool
+
+// DefaultRetryPredicate retries transport failures, 429 Too Many Requests and 5xx responses.
+// Combine it with broker-specific conditions in a custom predicate.
+func DefaultRetryPredicate(status int, body []byte, attempt int) bool {
+	return status == 0 || status == http.StatusTooManyRequests || status >= 500
+}
+
+// retryError marks an attempt the retry predicate asked to repeat.
+type retryError struct {
+	err error
+}
+
+func (e *retryError) Error() string { return e.err.Error() }
+func (e *retryError) Unwrap() error { return e.err }
+
+// NewClient creates a client for the gateway at baseURL. Options are applied in order:
+//
+//	client := projectx.NewClient(baseURL,
+//		projectx.WithTimeout(10*time.Second),
+//		projectx.WithRateLimit(50, 30*time.Second),
+//		projectx.WithLogger(log.Default()))
+//
+// See WithHTTPClient, WithTimeout, WithRateLimit, WithLogger and WithUserAgent.
+func NewClient(baseURL string, opts ...ClientOption) *Client {
+	c := &Client{
 		BaseURL:   baseURL,
 		UserAgent: "ProjectX-Go-Client/1.0",
+
+		retryMaxAttempts: 3,
+		retryDelay:       500 * time.Millisecond,
+
+		httpClient: http.DefaultClient,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
 }
 
+func (c *Client) logf(format string, v ...any) {
+	if c.logger != nil {
+		c.logger.Printf(format, v...)
+	}
+}
+
+// GetToken returns the JWT sent with authenticated requests. It is safe to call while
+// the token is being refreshed.
 func (c *Client) GetToken() string {
+	c.tokenMutex.RLock()
+	defer c.tokenMutex.RUnlock()
 	return c.Token
 }
 
+// SetToken sets the JWT sent with authenticated requests, e.g. one obtained outside the
+// client. It does not notify the OnTokenRefresh listeners.
+func (c *Client) SetToken(token string) {
+	c.tokenMutex.Lock()
+	c.Token = token
+	c.tokenMutex.Unlock()
+}
+
 // WithAutoRetry allows the client to retry on 401 Unauthorized by calling the provided auth function.
 func (c *Client) WithAutoRetry(authFn func() error) *Client {
 	c.authFunc = authFn
 	return c
 }
 
-func (c *Client) doRequest(method, endpoint string, body any, out any) error {
+// WithRetryPredicate enables retries for attempts the predicate selects. Without a predicate
+// requests are not retried. Because the predicate inspects the body, responses are read fully
+// into memory before decoding while one is set.
+func (c *Client) WithRetryPredicate(fn RetryPredicate) *Client {
+	c.retryPredicate = fn
+	return c
+}
+
+// WithRetryBackoff sets how many attempts a request gets in total and the delay before the
+// first retry, which doubles for each further retry. Defaults are 3 attempts and 500ms.
+func (c *Client) WithRetryBackoff(maxAttempts int, initialDelay time.Duration) *Client {
+	c.retryMaxAttempts = maxAttempts
+	c.retryDelay = initialDelay
+	return c
+}
+
+// send performs the request and decodes the response into out. When auth is false the
+// Authorization header and the 401 refresh are skipped; the auth endpoints use this so a
+// stale or revoked token is never sent on login.
+func (c *Client) send(method, endpoint string, body any, out any, auth bool) error {
+	return c.sendContext(context.Background(), method, endpoint, body, decodeJSON(out), auth)
+}
+
+// sendContext is send with a context and a custom decoder for the response body.
+func (c *Client) sendContext(ctx context.Context, method, endpoint string, body any, decode func(io.Reader) error, auth bool) error {
 	url := fmt.Sprintf("%s%s", c.BaseURL, endpoint)
 
-	var reqBody io.Reader
 	var bodyBytes []byte
 	var err error
 
@@ -48,43 +263,100 @@ func (c *Client) doRequest(method, endpoint string, body any, out any) error {
 		if err != nil {
 			return err
 		}
-		reqBody = bytes.NewReader(bodyBytes)
-	}
-
-	err = c.doOnce(method, url, reqBody, out)
-	if err == nil {
-		return nil
 	}
 
-	if errors.Is(err, ErrUnauthorized) && c.authFunc != nil {
-		if authErr := c.authFunc(); authErr != nil {
+	token := c.GetToken()
+	if auth && c.expiryCheck && token != "" && tokenExpired(token, c.expirySkew) {
+		if c.authFunc == nil {
+			return fmt.Errorf("%s %s: %w", method, endpoint, ErrTokenExpired)
+		}
+		c.logf("projectx: token expired, refreshing before %s %s", method, endpoint)
+		if authErr := c.refreshTokenFrom(token); authErr != nil {
 			return fmt.Errorf("auth refresh failed: %w", authErr)
 		}
+		token = c.GetToken()
+	}
 
-		if body != nil {
-			reqBody = bytes.NewReader(bodyBytes)
+	err = c.doWithRetry(ctx, method, url, bodyBytes, decode, auth)
+	if auth && errors.Is(err, ErrUnauthorized) && c.authFunc != nil {
+		c.logf("projectx: %s %s unauthorized, refreshing token", method, endpoint)
+		if authErr := c.refreshTokenFrom(token); authErr != nil {
+			err = fmt.Errorf("auth refresh failed: %w", authErr)
+		} else {
+			err = c.doWithRetry(ctx, method, url, bodyBytes, decode, auth)
 		}
-		return c.doOnce(method, url, reqBody, out)
 	}
 
+	if err != nil {
+		c.logf("projectx: %s %s failed: %v", method, endpoint, err)
+	}
 	return err
 }
 
-func (c *Client) doOnce(method, url string, body io.Reader, out any) error {
-	req, err := http.NewRequest(method, url, body)
+// doWithRetry repeats the request while the retry predicate asks for it, backing off
+// exponentially between attempts.
+func (c *Client) doWithRetry(ctx context.Context, method, url string, bodyBytes []byte, decode func(io.Reader) error, auth bool) error {
+	delay := c.retryDelay
+	for attempt := 1; ; attempt++ {
+		var reqBody io.Reader
+		if bodyBytes != nil {
+			reqBody = bytes.NewReader(bodyBytes)
+		}
+
+		err := c.doOnce(ctx, method, url, reqBody, decode, auth, attempt)
+		var retry *retryError
+		if !errors.As(err, &retry) {
+			return err
+		}
+		if attempt >= c.retryMaxAttempts {
+			return retry.err
+		}
+		c.logf("projectx: retrying %s %s in %s after attempt %d: %v", method, url, delay, attempt, retry.err)
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+func (c *Client) doOnce(ctx context.Context, method, url string, body io.Reader, decode func(io.Reader) error, auth bool, attempt int) error {
+	if c.inflight != nil {
+		if err := c.inflight.acquire(ctx); err != nil {
+			return err
+		}
+		defer c.inflight.release()
+	}
+	if c.limiter != nil {
+		if err := c.limiter.wait(ctx); err != nil {
+			return err
+		}
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
 	if err != nil {
 		return err
 	}
 
 	req.Header.Set("Content-Type", "application/json")
 	req.Header.Set("Accept", "text/plain")
-	if c.Token != "" {
-		req.Header.Set("Authorization", "Bearer "+c.Token)
+	if token := c.GetToken(); auth && token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
 	}
 	req.Header.Set("User-Agent", c.UserAgent)
 
-	resp, err := http.DefaultClient.Do(req)
+	resp, err := c.httpClient.Do(req)
 	if err != nil {
+		if c.retryPredicate != nil && c.retryPredicate(0, nil, attempt) {
+			return &retryError{err}
+		}
 		return err
 	}
 	defer resp.Body.Close()
@@ -93,9 +365,39 @@ func (c *Client) doOnce(method, url string, body io.Reader, out any) error {
 		return ErrUnauthorized
 	}
 
-	if out != nil {
-		return json.NewDecoder(resp.Body).Decode(out)
+	if c.retryPredicate == nil {
+		return decode(resp.Body)
 	}
 
-	return nil
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		err = wrapDecodeError(err)
+		if c.retryPredicate(0, data, attempt) {
+			return &retryError{err}
+		}
+		return err
+	}
+	if c.retryPredicate(resp.StatusCode, data, attempt) {
+		return &retryError{fmt.Errorf("request failed with status %d", resp.StatusCode)}
+	}
+	return decode(bytes.NewReader(data))
+}
+
+// decodeJSON returns a decoder that reads a single JSON value into out.
+// A nil out discards the body.
+func decodeJSON(out any) func(io.Reader) error {
+	return func(r io.Reader) error {
+		if out == nil {
+			return nil
+		}
+		return wrapDecodeError(json.NewDecoder(r).Decode(out))
+	}
+}
+
+// wrapDecodeError marks errors caused by a body that ended mid-value as ErrTruncatedResponse.
+func wrapDecodeError(err error) error {
+	if errors.Is(err, io.ErrUnexpectedEOF) {
+		return fmt.Errorf("%w: %w", ErrTruncatedResponse, err)
+	}
+	return err
 }
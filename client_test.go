@@ -0,0 +1,63 @@
+package projectx
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// truncatingServer declares the full length of body but drops the connection after
+// half of it, as a proxy cutting off a response would.
+func truncatingServer(t *testing.T, body string) (*httptest.Server, *atomic.Int32) {
+	t.Helper()
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("Content-Length", strconv.Itoa(len(body)))
+		w.Write([]byte(body[:len(body)/2]))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &requests
+}
+
+func TestTruncatedResponse(t *testing.T) {
+	const body = `{"success":true,"errorCode":0,"errorMessage":null,"bars":[` +
+		`{"t":"2024-01-02T14:30:00Z","o":4700.25,"h":4701,"l":4699.5,"c":4700.75,"v":1200},` +
+		`{"t":"2024-01-02T14:31:00Z","o":4700.75,"h":4702,"l":4700.5,"c":4701.5,"v":900}]}`
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	req := HistoryRequest{StartTime: start, EndTime: start.Add(time.Hour), Unit: TimeUnitMinute, UnitNumber: 1, Limit: 10}
+
+	calls := map[string]func(c *Client) error{
+		"decoded": func(c *Client) error {
+			_, err := c.GetHistoricalBars(req)
+			return err
+		},
+		"streamed": func(c *Client) error {
+			return c.GetHistoricalBarsStream(context.Background(), req, func(HistoryBar) error { return nil })
+		},
+	}
+	for name, call := range calls {
+		t.Run(name, func(t *testing.T) {
+			srv, requests := truncatingServer(t, body)
+			err := call(NewClient(srv.URL))
+			if !errors.Is(err, ErrTruncatedResponse) {
+				t.Fatalf("got error %v, want ErrTruncatedResponse", err)
+			}
+			if !errors.Is(err, io.ErrUnexpectedEOF) {
+				t.Errorf("error %v does not wrap io.ErrUnexpectedEOF", err)
+			}
+			if !IsRetryable(err) {
+				t.Errorf("error %v not retryable", err)
+			}
+			if n := requests.Load(); n != 1 {
+				t.Errorf("sent %d requests, want 1", n)
+			}
+		})
+	}
+}
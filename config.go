@@ -0,0 +1,157 @@
+package projectx
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"time"
+)
+
+// Config gathers client and hub settings for declarative setups, e.g. loaded from a
+// YAML or JSON file by the caller. Zero values keep the library defaults.
+type Config struct {
+	BaseURL    string `json:"baseUrl" yaml:"baseUrl"`       // Gateway REST base URL, required
+	HubURL     string `json:"hubUrl" yaml:"hubUrl"`         // Market hub, DefaultMarketHubURL when empty
+	UserHubURL string `json:"userHubUrl" yaml:"userHubUrl"` // User hub, DefaultUserHubURL when empty
+
+	Username string `json:"username" yaml:"username"` // Login user name; set together with APIKey
+	APIKey   string `json:"apiKey" yaml:"apiKey"`     // Login API key
+
+	Timeout          time.Duration `json:"timeout" yaml:"timeout"`                   // Per request attempt, see WithTimeout
+	NegotiateTimeout time.Duration `json:"negotiateTimeout" yaml:"negotiateTimeout"` // Hub negotiation, see WithNegotiateTimeout
+	TokenExpirySkew  time.Duration `json:"tokenExpirySkew" yaml:"tokenExpirySkew"`   // Enables WithTokenExpiryCheck when positive
+	KeepAlive        time.Duration `json:"keepAlive" yaml:"keepAlive"`               // Hub ping interval, see WithKeepAlive
+	ServerTimeout    time.Duration `json:"serverTimeout" yaml:"serverTimeout"`       // Hub silence before reconnecting, see WithKeepAlive
+
+	RateLimit         int           `json:"rateLimit" yaml:"rateLimit"`                 // Requests per RateLimitInterval, 0 for none
+	RateLimitInterval time.Duration `json:"rateLimitInterval" yaml:"rateLimitInterval"` // Window for RateLimit
+	MaxConcurrency    int           `json:"maxConcurrency" yaml:"maxConcurrency"`       // Requests in flight at once, 0 for unlimited
+
+	RetryMaxAttempts  int           `json:"retryMaxAttempts" yaml:"retryMaxAttempts"`   // Enables DefaultRetryPredicate when above 1
+	RetryInitialDelay time.Duration `json:"retryInitialDelay" yaml:"retryInitialDelay"` // Delay before the first retry
+
+	DryRun bool `json:"dryRun" yaml:"dryRun"` // Skip order and position changes, see WithDryRun
+}
+
+// Validate reports every missing or contradictory setting.
+func (c Config) Validate() error {
+	var errs []error
+	if c.BaseURL == "" {
+		errs = append(errs, errors.New("BaseURL is required"))
+	} else if u, err := url.Parse(c.BaseURL); err != nil || u.Scheme == "" || u.Host == "" {
+		errs = append(errs, fmt.Errorf("BaseURL %q is not an absolute URL", c.BaseURL))
+	}
+	for name, hub := range map[string]string{"HubURL": c.HubURL, "UserHubURL": c.UserHubURL} {
+		if hub == "" {
+			continue
+		}
+		if u, err := url.Parse(hub); err != nil || u.Scheme == "" || u.Host == "" {
+			errs = append(errs, fmt.Errorf("%s %q is not an absolute URL", name, hub))
+		}
+	}
+	if (c.Username == "") != (c.APIKey == "") {
+		errs = append(errs, errors.New("Username and APIKey must be set together"))
+	}
+	if c.Timeout < 0 || c.NegotiateTimeout < 0 || c.TokenExpirySkew < 0 || c.KeepAlive < 0 || c.ServerTimeout < 0 {
+		errs = append(errs, errors.New("timeouts must not be negative"))
+	}
+	if keepAlive, timeout := c.keepAlive(); timeout <= keepAlive {
+		errs = append(errs, fmt.Errorf("ServerTimeout %s must exceed KeepAlive %s", timeout, keepAlive))
+	}
+	if c.RateLimit < 0 || c.MaxConcurrency < 0 {
+		errs = append(errs, errors.New("RateLimit and MaxConcurrency must not be negative"))
+	}
+	if c.RateLimit > 0 && c.RateLimitInterval <= 0 {
+		errs = append(errs, errors.New("RateLimit requires a positive RateLimitInterval"))
+	}
+	if c.RetryMaxAttempts < 0 || c.RetryInitialDelay < 0 {
+		errs = append(errs, errors.New("retry settings must not be negative"))
+	}
+	if c.RetryInitialDelay > 0 && c.RetryMaxAttempts <= 1 {
+		errs = append(errs, errors.New("RetryInitialDelay requires RetryMaxAttempts above 1"))
+	}
+	if c.TokenExpirySkew > 0 && c.Username == "" {
+		errs = append(errs, errors.New("TokenExpirySkew requires credentials to refresh the token"))
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// NewClientFromConfig validates cfg and creates a client with the corresponding options.
+// With credentials set, the client re-authenticates automatically when the token is
+// rejected; call Login once before use.
+func NewClientFromConfig(cfg Config) (*Client, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+
+	var opts []ClientOption
+	if cfg.Timeout > 0 {
+		opts = append(opts, WithTimeout(cfg.Timeout))
+	}
+	if cfg.RateLimit > 0 {
+		opts = append(opts, WithRateLimit(cfg.RateLimit, cfg.RateLimitInterval))
+	}
+	if cfg.MaxConcurrency > 0 {
+		opts = append(opts, WithMaxConcurrency(cfg.MaxConcurrency))
+	}
+	if cfg.TokenExpirySkew > 0 {
+		opts = append(opts, WithTokenExpiryCheck(cfg.TokenExpirySkew))
+	}
+	if cfg.DryRun {
+		opts = append(opts, WithDryRun(true))
+	}
+	c := NewClient(cfg.BaseURL, opts...)
+
+	if cfg.RetryMaxAttempts > 1 {
+		delay := cfg.RetryInitialDelay
+		if delay == 0 {
+			delay = c.retryDelay
+		}
+		c.WithRetryPredicate(DefaultRetryPredicate).WithRetryBackoff(cfg.RetryMaxAttempts, delay)
+	}
+	if cfg.Username != "" {
+		c.WithAutoRetry(func() error {
+			return c.Login(cfg.Username, cfg.APIKey)
+		})
+	}
+	return c, nil
+}
+
+// MarketHubOptions returns the SignalROptions for NewSignalRClient described by cfg.
+func (c Config) MarketHubOptions() []SignalROption {
+	return c.hubOptions(c.HubURL)
+}
+
+// UserHubOptions returns the SignalROptions for NewUserHubClient described by cfg.
+func (c Config) UserHubOptions() []SignalROption {
+	return c.hubOptions(c.UserHubURL)
+}
+
+func (c Config) hubOptions(hubURL string) []SignalROption {
+	var opts []SignalROption
+	if hubURL != "" {
+		opts = append(opts, WithHubURL(hubURL))
+	}
+	if c.NegotiateTimeout > 0 {
+		opts = append(opts, WithNegotiateTimeout(c.NegotiateTimeout))
+	}
+	if c.KeepAlive > 0 || c.ServerTimeout > 0 {
+		opts = append(opts, WithKeepAlive(c.keepAlive()))
+	}
+	return opts
+}
+
+// keepAlive returns the hub keep-alive settings with defaults for unset values.
+func (c Config) keepAlive() (interval, serverTimeout time.Duration) {
+	interval, serverTimeout = DefaultKeepAliveInterval, DefaultServerTimeout
+	if c.KeepAlive > 0 {
+		interval = c.KeepAlive
+	}
+	if c.ServerTimeout > 0 {
+		serverTimeout = c.ServerTimeout
+	}
+	return interval, serverTimeout
+}
@@ -0,0 +1,82 @@
+package projectx
+
+import "sync"
+
+// hydrateWorkers bounds concurrent requests made by HydrateContracts.
+const hydrateWorkers = 4
+
+// contractCache holds contracts by ID. The zero value is ready to use.
+type contractCache struct {
+	mutex     sync.RWMutex
+	contracts map[string]Contract
+}
+
+func (cc *contractCache) get(id string) (Contract, bool) {
+	cc.mutex.RLock()
+	defer cc.mutex.RUnlock()
+	contract, ok := cc.contracts[id]
+	return contract, ok
+}
+
+func (cc *contractCache) put(contract Contract) {
+	cc.mutex.Lock()
+	defer cc.mutex.Unlock()
+	if cc.contracts == nil {
+		cc.contracts = make(map[string]Contract)
+	}
+	cc.contracts[contract.ID] = contract
+}
+
+// GetContractByIDCached returns the contract from the client's cache, fetching and
+// caching it with GetContractByID on a miss. Contract metadata does not change
+// intraday, so entries never expire.
+func (c *Client) GetContractByIDCached(contractID string) (*Contract, error) {
+	if contract, ok := c.contracts.get(contractID); ok {
+		return &contract, nil
+	}
+	contract, err := c.GetContractByID(contractID)
+	if err != nil {
+		return nil, err
+	}
+	c.contracts.put(*contract)
+	return contract, nil
+}
+
+// HydrateContracts fetches the contracts not yet cached, a few at a time and through the
+// client's rate limiter, so later GetContractByIDCached calls are hits. Use it at startup
+// for a known universe. The error is a *MultiError listing every ID that could not be fetched.
+func (c *Client) HydrateContracts(ids []string) error {
+	missing := make(chan string)
+	go func() {
+		defer close(missing)
+		seen := make(map[string]bool, len(ids))
+		for _, id := range ids {
+			if _, ok := c.contracts.get(id); ok || seen[id] {
+				continue
+			}
+			seen[id] = true
+			missing <- id
+		}
+	}()
+
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+		errs  = &MultiError{Op: "fetch contracts", Kind: "contract"}
+	)
+	for i := 0; i < hydrateWorkers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range missing {
+				_, err := c.GetContractByIDCached(id)
+				mutex.Lock()
+				errs.Total++
+				errs.add(id, err)
+				mutex.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errs.err()
+}
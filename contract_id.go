@@ -0,0 +1,71 @@
+package projectx
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ContractIDParts is a contract ID such as "CON.F.US.ENQ.M25" split into its components.
+type ContractIDParts struct {
+	Type   string     // Instrument type, "F" for futures
+	Region string     // Exchange region, e.g. "US"
+	Symbol string     // Product root, e.g. "ENQ"
+	Month  time.Month // Delivery month from the month code
+	Year   int        // Four-digit delivery year
+}
+
+// futuresMonthCodes maps the standard futures month letters to months.
+var futuresMonthCodes = map[byte]time.Month{
+	'F': time.January,
+	'G': time.February,
+	'H': time.March,
+	'J': time.April,
+	'K': time.May,
+	'M': time.June,
+	'N': time.July,
+	'Q': time.August,
+	'U': time.September,
+	'V': time.October,
+	'X': time.November,
+	'Z': time.December,
+}
+
+// ParseContractID splits a contract ID of the form CON.<type>.<region>.<symbol>.<month code><yy>.
+// Two-digit years are interpreted as 20yy.
+func ParseContractID(id string) (ContractIDParts, error) {
+	parts := strings.Split(id, ".")
+	if len(parts) != 5 || parts[0] != "CON" {
+		return ContractIDParts{}, fmt.Errorf("invalid contract ID %q", id)
+	}
+
+	expiry := parts[4]
+	if len(expiry) < 2 {
+		return ContractIDParts{}, fmt.Errorf("invalid contract expiry %q in %q", expiry, id)
+	}
+	month, ok := futuresMonthCodes[expiry[0]]
+	if !ok {
+		return ContractIDParts{}, fmt.Errorf("invalid month code %q in %q", expiry[:1], id)
+	}
+	year, err := strconv.Atoi(expiry[1:])
+	if err != nil {
+		return ContractIDParts{}, fmt.Errorf("invalid contract year %q in %q", expiry[1:], id)
+	}
+	if year < 100 {
+		year += 2000
+	}
+
+	return ContractIDParts{
+		Type:   parts[1],
+		Region: parts[2],
+		Symbol: parts[3],
+		Month:  month,
+		Year:   year,
+	}, nil
+}
+
+// SymbolID returns the symbol ID shared by every expiry of the product, e.g. "F.US.ENQ".
+func (p ContractIDParts) SymbolID() string {
+	return p.Type + "." + p.Region + "." + p.Symbol
+}
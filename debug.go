@@ -0,0 +1,138 @@
+package projectx
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ClientDebug is a snapshot of a Client's state for troubleshooting. It never holds
+// the token itself, only whether one is set and when it expires.
+type ClientDebug struct {
+	BaseURL        string
+	HasToken       bool
+	TokenExpiry    time.Time // Zero when there is no token or its expiry cannot be read
+	AutoAuth       bool      // Whether the client re-authenticates on 401
+	RetryAttempts  int
+	Timeout        time.Duration
+	RateLimited    bool
+	MaxConcurrency int  // Zero when unlimited
+	DryRun         bool // Whether mutating calls are skipped
+}
+
+// Debug returns a redacted snapshot of the client's state, suitable for logs and
+// support requests.
+func (c *Client) Debug() ClientDebug {
+	d := ClientDebug{
+		BaseURL:        redactURL(c.BaseURL),
+		HasToken:       c.GetToken() != "",
+		AutoAuth:       c.authFunc != nil,
+		RetryAttempts:  c.retryMaxAttempts,
+		Timeout:        c.timeout,
+		RateLimited:    c.limiter != nil,
+		MaxConcurrency: cap(c.inflight),
+		DryRun:         c.dryRun,
+	}
+	if exp, err := c.TokenExpiry(); err == nil {
+		d.TokenExpiry = exp
+	}
+	return d
+}
+
+func (d ClientDebug) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "baseURL=%s token=%s", d.BaseURL, tokenState(d.HasToken, d.TokenExpiry))
+	fmt.Fprintf(&b, " autoAuth=%t retries=%d timeout=%s rateLimited=%t", d.AutoAuth, d.RetryAttempts, d.Timeout, d.RateLimited)
+	if d.MaxConcurrency > 0 {
+		fmt.Fprintf(&b, " maxConcurrency=%d", d.MaxConcurrency)
+	}
+	if d.DryRun {
+		b.WriteString(" dryRun=true")
+	}
+	return b.String()
+}
+
+// SignalRDebug is a snapshot of a SignalRClient's state for troubleshooting. It never
+// holds the token itself, only whether one is set and when it expires.
+type SignalRDebug struct {
+	HubURL         string
+	HasToken       bool
+	TokenExpiry    time.Time // Zero when there is no token or its expiry cannot be read
+	Connected      bool
+	ConnectionID   string
+	Reconnecting   bool
+	ReconnectCount int
+	GaveUp         bool // Whether the reconnect policy has given up
+	Subscriptions  int  // Number of subscribed contracts
+	LastUpdate     time.Time
+}
+
+// Debug returns a redacted snapshot of the connection's state, suitable for logs and
+// support requests.
+func (c *SignalRClient) Debug() SignalRDebug {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	d := SignalRDebug{
+		HubURL:         redactURL(c.config.hubURL),
+		HasToken:       c.token != "",
+		Connected:      c.isConnected,
+		ConnectionID:   c.connectionID,
+		Reconnecting:   c.reconnecting,
+		ReconnectCount: c.reconnectCount,
+		GaveUp:         c.gaveUp,
+		Subscriptions:  len(c.subscriptions),
+	}
+	if exp, err := TokenExpiry(c.token); err == nil {
+		d.TokenExpiry = exp
+	}
+	for _, t := range c.lastUpdate {
+		if t.After(d.LastUpdate) {
+			d.LastUpdate = t
+		}
+	}
+	return d
+}
+
+func (d SignalRDebug) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "hubURL=%s token=%s connected=%t", d.HubURL, tokenState(d.HasToken, d.TokenExpiry), d.Connected)
+	if d.ConnectionID != "" {
+		fmt.Fprintf(&b, " connectionID=%s", d.ConnectionID)
+	}
+	fmt.Fprintf(&b, " reconnecting=%t reconnects=%d gaveUp=%t subscriptions=%d", d.Reconnecting, d.ReconnectCount, d.GaveUp, d.Subscriptions)
+	if !d.LastUpdate.IsZero() {
+		fmt.Fprintf(&b, " lastUpdate=%s", d.LastUpdate.UTC().Format(time.RFC3339))
+	}
+	return b.String()
+}
+
+// tokenState describes a token without revealing it.
+func tokenState(has bool, expiry time.Time) string {
+	switch {
+	case !has:
+		return "none"
+	case expiry.IsZero():
+		return "set"
+	case !time.Now().Before(expiry):
+		return "expired@" + expiry.UTC().Format(time.RFC3339)
+	default:
+		return "expires@" + expiry.UTC().Format(time.RFC3339)
+	}
+}
+
+// redactURL masks credentials and query parameters, which may carry an access token.
+func redactURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "<invalid>"
+	}
+	if u.User != nil {
+		u.User = url.User("xxxxx")
+	}
+	if u.RawQuery != "" {
+		u.RawQuery = "redacted"
+	}
+	return u.String()
+}
@@ -0,0 +1,73 @@
+package projectx
+
+import (
+	"fmt"
+	"time"
+)
+
+// WithDryRun makes the client skip order placement, modification and cancellation and
+// position closes: each is logged through the Logger and answered with a synthetic
+// success instead of reaching the gateway. Read-only calls are sent as usual, so a
+// strategy can run against production data without risking real orders.
+//
+// Synthetic placements return negative order IDs and have OrderResponse.DryRun set;
+// audit entries of skipped submissions, position closes included, have DryRun set.
+// PlaceOrderAwait and PlaceOrderAndFetch return at once with an open order built from
+// the request. No order exists on the gateway, so searches and the user hub never see
+// these orders.
+func WithDryRun(enabled bool) ClientOption {
+	return func(c *Client) {
+		c.dryRun = enabled
+	}
+}
+
+// DryRun reports whether the client is in dry-run mode, see WithDryRun.
+func (c *Client) DryRun() bool {
+	return c.dryRun
+}
+
+// dryRunOrder logs the placement of order and returns its synthetic response.
+func (c *Client) dryRunOrder(order OrderRequest) *OrderResponse {
+	resp := &OrderResponse{
+		OrderID:    int(c.dryRunOrderID.Add(-1)),
+		DryRun:     true,
+		ReceivedAt: time.Now(),
+	}
+	resp.Success = true
+
+	details := fmt.Sprintf("%s %d %s", order.Side, order.Size, order.ContractID)
+	if name, ok := OrderTypeName[order.Type]; ok {
+		details += " " + name
+	}
+	if order.LimitPrice != nil {
+		details += fmt.Sprintf(" limit %v", *order.LimitPrice)
+	}
+	if order.StopPrice != nil {
+		details += fmt.Sprintf(" stop %v", *order.StopPrice)
+	}
+	if order.TrailPrice != nil {
+		details += fmt.Sprintf(" trail %v", *order.TrailPrice)
+	}
+	c.logf("dry run: place %s on account %d as order %d", details, order.AccountID, resp.OrderID)
+	return resp
+}
+
+// dryRunOrderInfo builds the working order a synthetic placement stands for.
+func dryRunOrderInfo(order OrderRequest, resp *OrderResponse) OrderInfo {
+	info := OrderInfo{
+		ID:                resp.OrderID,
+		AccountID:         order.AccountID,
+		ContractID:        order.ContractID,
+		CreationTimestamp: resp.ReceivedAt.UTC(),
+		Status:            OrderStatusOpen,
+		Type:              order.Type,
+		Side:              order.Side,
+		Size:              order.Size,
+		LimitPrice:        clonePtr(order.LimitPrice),
+		StopPrice:         clonePtr(order.StopPrice),
+	}
+	if order.CustomTag != nil {
+		info.CustomTag = *order.CustomTag
+	}
+	return info
+}
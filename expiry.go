@@ -0,0 +1,160 @@
+package projectx
+
+import (
+	"sync"
+	"time"
+)
+
+// Expiry approximates the last trading day of the contract as the third Friday of the
+// delivery month, at midnight UTC. That matches equity index and currency futures;
+// other products (energy contracts expire in the month before delivery) differ, so
+// treat the result as a roll reminder rather than an exchange calendar.
+func (p ContractIDParts) Expiry() time.Time {
+	first := time.Date(p.Year, p.Month, 1, 0, 0, 0, 0, time.UTC)
+	offset := (int(time.Friday) - int(first.Weekday()) + 7) % 7
+	return first.AddDate(0, 0, offset+14)
+}
+
+// ContractExpiry returns the approximate expiry of the contract parsed from its ID.
+func ContractExpiry(contract Contract) (time.Time, error) {
+	parts, err := ParseContractID(contract.ID)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return parts.Expiry(), nil
+}
+
+// ExpiresWithin reports whether the contract expires within d from now, or has already
+// expired. Contracts whose ID cannot be parsed report false.
+func ExpiresWithin(contract Contract, d time.Duration) bool {
+	expiry, err := ContractExpiry(contract)
+	if err != nil {
+		return false
+	}
+	return time.Until(expiry) <= d
+}
+
+// ExpiryCallback is called when a watched contract comes within the horizon of its expiry.
+type ExpiryCallback func(contract Contract, expiry time.Time)
+
+// ExpiryWatcher periodically checks watched contracts, e.g. those subscribed or held,
+// and calls back once per contract when it nears expiry so a strategy can roll.
+type ExpiryWatcher struct {
+	mutex     sync.Mutex
+	horizon   time.Duration
+	interval  time.Duration
+	contracts map[string]Contract
+	alerted   map[string]bool
+	callback  ExpiryCallback
+	stop      chan struct{}
+}
+
+// NewExpiryWatcher creates a watcher alerting horizon before expiry, checking hourly.
+// Nothing runs until Start is called.
+func NewExpiryWatcher(horizon time.Duration, callback ExpiryCallback) *ExpiryWatcher {
+	return &ExpiryWatcher{
+		horizon:   horizon,
+		interval:  time.Hour,
+		contracts: make(map[string]Contract),
+		alerted:   make(map[string]bool),
+		callback:  callback,
+	}
+}
+
+// WithCheckInterval sets how often watched contracts are checked. Call it before Start.
+// Intervals of zero or less are ignored; the default is an hour.
+func (w *ExpiryWatcher) WithCheckInterval(interval time.Duration) *ExpiryWatcher {
+	if interval <= 0 {
+		return w
+	}
+	w.mutex.Lock()
+	w.interval = interval
+	w.mutex.Unlock()
+	return w
+}
+
+// Watch adds contracts to the watch list.
+func (w *ExpiryWatcher) Watch(contracts ...Contract) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	for _, contract := range contracts {
+		w.contracts[contract.ID] = contract
+	}
+}
+
+// Unwatch removes a contract, e.g. after it has been rolled.
+func (w *ExpiryWatcher) Unwatch(contractID string) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	delete(w.contracts, contractID)
+	delete(w.alerted, contractID)
+}
+
+// Check alerts for every watched contract within the horizon that has not been alerted yet.
+// Start calls it immediately and then every check interval.
+func (w *ExpiryWatcher) Check() {
+	type alert struct {
+		contract Contract
+		expiry   time.Time
+	}
+	var alerts []alert
+
+	w.mutex.Lock()
+	for id, contract := range w.contracts {
+		if w.alerted[id] {
+			continue
+		}
+		expiry, err := ContractExpiry(contract)
+		if err != nil || time.Until(expiry) > w.horizon {
+			continue
+		}
+		w.alerted[id] = true
+		alerts = append(alerts, alert{contract, expiry})
+	}
+	callback := w.callback
+	w.mutex.Unlock()
+
+	if callback == nil {
+		return
+	}
+	for _, a := range alerts {
+		callback(a.contract, a.expiry)
+	}
+}
+
+// Start begins checking in the background. It does nothing if already started.
+func (w *ExpiryWatcher) Start() {
+	w.mutex.Lock()
+	if w.stop != nil {
+		w.mutex.Unlock()
+		return
+	}
+	stop := make(chan struct{})
+	w.stop = stop
+	interval := w.interval
+	w.mutex.Unlock()
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		w.Check()
+		for {
+			select {
+			case <-stop:
+				return
+			case <-ticker.C:
+				w.Check()
+			}
+		}
+	}()
+}
+
+// Stop ends background checking.
+func (w *ExpiryWatcher) Stop() {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+	if w.stop != nil {
+		close(w.stop)
+		w.stop = nil
+	}
+}
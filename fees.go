@@ -0,0 +1,40 @@
+package projectx
+
+// FeeSchedule maps a contract ID or symbol ID (e.g. "CON.F.US.ENQ.M25" or
+// "F.US.ENQ") to the fee charged per contract per side, including commissions
+// and exchange fees. Keying by symbol ID covers every expiry of a product.
+type FeeSchedule map[string]float64
+
+// FeeFor returns the per-contract, per-side fee for the contract. The contract ID
+// takes precedence over the symbol ID. The second result is false when neither is listed.
+func (s FeeSchedule) FeeFor(contract Contract) (float64, bool) {
+	return scheduleLookup(s, contract)
+}
+
+// scheduleLookup returns the value for the contract ID, falling back to the symbol ID.
+func scheduleLookup[S ~map[string]float64](s S, contract Contract) (float64, bool) {
+	if v, ok := s[contract.ID]; ok {
+		return v, true
+	}
+	if contract.SymbolID != "" {
+		if v, ok := s[contract.SymbolID]; ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// EstimateFees returns the estimated fees for executing size contracts on one side.
+// The sign of size is ignored. Contracts missing from the schedule estimate zero.
+func (s FeeSchedule) EstimateFees(contract Contract, size int) float64 {
+	fee, _ := s.FeeFor(contract)
+	if size < 0 {
+		size = -size
+	}
+	return fee * float64(size)
+}
+
+// EstimateRoundTurnFees returns the estimated fees for opening and closing size contracts.
+func (s FeeSchedule) EstimateRoundTurnFees(contract Contract, size int) float64 {
+	return 2 * s.EstimateFees(contract, size)
+}
@@ -0,0 +1,54 @@
+package projectx
+
+import (
+	"sort"
+	"time"
+)
+
+// GapRange is a run of missing bars in a series. Start is the time of the first missing
+// bar and End the end of the last one, so [Start, End) can be refetched as is.
+type GapRange struct {
+	Start   time.Time
+	End     time.Time
+	Missing int // Number of missing bars
+}
+
+// SessionCalendar reports whether the market is open at t. DetectGaps does not flag
+// bars missing while the market is closed, e.g. overnight or at weekends.
+type SessionCalendar func(t time.Time) bool
+
+// DetectGaps scans bars for missing intervals of the expected bar period and returns
+// them oldest first. Bars may be in either order. Without a calendar every missing
+// interval is reported, including session breaks. Only gaps between bars are found;
+// missing bars before the first or after the last are not.
+func DetectGaps(bars []HistoryBar, expected time.Duration, calendar SessionCalendar) []GapRange {
+	if expected <= 0 || len(bars) < 2 {
+		return nil
+	}
+
+	times := make([]time.Time, len(bars))
+	for i, bar := range bars {
+		times[i] = bar.Time
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	var gaps []GapRange
+	var current *GapRange
+	for i := 1; i < len(times); i++ {
+		for t := times[i-1].Add(expected); t.Before(times[i]); t = t.Add(expected) {
+			if calendar != nil && !calendar(t) {
+				current = nil
+				continue
+			}
+			if current == nil {
+				gaps = append(gaps, GapRange{Start: t})
+				current = &gaps[len(gaps)-1]
+			}
+			current.End = t.Add(expected)
+			current.Missing++
+		}
+		// A bar that is present ends the current gap
+		current = nil
+	}
+	return gaps
+}
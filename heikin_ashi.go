@@ -0,0 +1,124 @@
+package projectx
+
+import (
+	"sync"
+	"time"
+)
+
+// HeikinAshiAggregator emits Heikin-Ashi bars. It builds regular time bars with a
+// MarketDataManager and smooths each completed bar using the previous Heikin-Ashi bar.
+// Bars are delivered as HistoryBar values through the standard callback; their OHLC
+// fields hold the Heikin-Ashi values and Vol the raw volume.
+//
+// The first bar has no predecessor and is only approximate. Call Seed with recent
+// history before streaming so live bars continue the historical series exactly.
+type HeikinAshiAggregator struct {
+	mutex    sync.Mutex
+	raw      *MarketDataManager
+	prev     *HistoryBar
+	callback MarketDataErrorCallback
+}
+
+func NewHeikinAshiAggregator(contractID string, barPeriodMinutes int, callback MarketDataCallback) *HeikinAshiAggregator {
+	a := &HeikinAshiAggregator{callback: callback.ToErrorCallback()}
+	a.raw = NewMarketDataManagerWithErrors(contractID, barPeriodMinutes, a.onRawBar)
+	return a
+}
+
+func (a *HeikinAshiAggregator) OnQuote(contractID string, data map[string]interface{}) {
+	a.raw.OnQuote(contractID, data)
+}
+
+func (a *HeikinAshiAggregator) OnTrade(contractID string, data map[string]interface{}) {
+	a.raw.OnTrade(contractID, data)
+}
+
+func (a *HeikinAshiAggregator) OnDepth(contractID string, data map[string]interface{}) {
+	a.raw.OnDepth(contractID, data)
+}
+
+func (a *HeikinAshiAggregator) ContractID() string {
+	return a.raw.ContractID()
+}
+
+// WithClock sets the time source of the underlying bars, see MarketDataManager.WithClock.
+func (a *HeikinAshiAggregator) WithClock(clock func() time.Time) *HeikinAshiAggregator {
+	a.raw.WithClock(clock)
+	return a
+}
+
+// Flush emits the Heikin-Ashi bar for the raw bar being built.
+func (a *HeikinAshiAggregator) Flush() {
+	a.raw.Flush()
+}
+
+// Tick closes the raw bar if its period has elapsed, see MarketDataManager.Tick.
+func (a *HeikinAshiAggregator) Tick(now time.Time) {
+	a.raw.Tick(now)
+}
+
+// CallbackErrors returns how many bar callbacks have failed and the most recent error.
+func (a *HeikinAshiAggregator) CallbackErrors() (int, error) {
+	return a.raw.CallbackErrors()
+}
+
+// Seed replays raw historical bars, oldest first, to establish the previous
+// Heikin-Ashi bar. No callbacks are invoked.
+func (a *HeikinAshiAggregator) Seed(bars []HistoryBar) {
+	ha := HeikinAshi(bars)
+	if len(ha) == 0 {
+		return
+	}
+	a.mutex.Lock()
+	last := ha[len(ha)-1]
+	a.prev = &last
+	a.mutex.Unlock()
+}
+
+// PreviousBar returns the most recent Heikin-Ashi bar, from Seed or the live feed.
+func (a *HeikinAshiAggregator) PreviousBar() (HistoryBar, bool) {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	if a.prev == nil {
+		return HistoryBar{}, false
+	}
+	return *a.prev, true
+}
+
+func (a *HeikinAshiAggregator) onRawBar(bar HistoryBar) error {
+	a.mutex.Lock()
+	ha := heikinAshiBar(a.prev, bar)
+	a.prev = &ha
+	a.mutex.Unlock()
+
+	if a.callback == nil {
+		return nil
+	}
+	return a.callback(ha)
+}
+
+// heikinAshiBar derives a Heikin-Ashi bar from a raw bar and the previous Heikin-Ashi bar.
+// Without a previous bar the open is the midpoint of the raw open and close.
+func heikinAshiBar(prev *HistoryBar, raw HistoryBar) HistoryBar {
+	ha := raw
+	ha.Close = (raw.Open + raw.High + raw.Low + raw.Close) / 4
+	if prev == nil {
+		ha.Open = (raw.Open + raw.Close) / 2
+	} else {
+		ha.Open = (prev.Open + prev.Close) / 2
+	}
+	ha.High = max(raw.High, ha.Open, ha.Close)
+	ha.Low = min(raw.Low, ha.Open, ha.Close)
+	return ha
+}
+
+// HeikinAshi converts raw bars, ordered oldest first, into Heikin-Ashi bars.
+func HeikinAshi(bars []HistoryBar) []HistoryBar {
+	ha := make([]HistoryBar, len(bars))
+	var prev *HistoryBar
+	for i, bar := range bars {
+		ha[i] = heikinAshiBar(prev, bar)
+		prev = &ha[i]
+	}
+	return ha
+}
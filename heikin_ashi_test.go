@@ -0,0 +1,82 @@
+package projectx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestHeikinAshi(t *testing.T) {
+	raw := []HistoryBar{
+		{Open: 10, High: 12, Low: 9, Close: 11},
+		{Open: 11, High: 14, Low: 10, Close: 13},
+		{Open: 13, High: 13.5, Low: 8, Close: 9},
+		{Open: 9, High: 9.5, Low: 8.5, Close: 9},
+	}
+	// Close is the raw OHLC average, open the midpoint of the previous HA open and
+	// close (of the raw open and close for the first bar), high and low include both.
+	want := []HistoryBar{
+		{Open: 10.5, High: 12, Low: 9, Close: 10.5},
+		{Open: 10.5, High: 14, Low: 10, Close: 12},
+		{Open: 11.25, High: 13.5, Low: 8, Close: 10.875},
+		{Open: 11.0625, High: 11.0625, Low: 8.5, Close: 9}, // HA open above the raw high
+	}
+
+	got := HeikinAshi(raw)
+	if len(got) != len(want) {
+		t.Fatalf("got %d bars, want %d", len(got), len(want))
+	}
+	for i := range want {
+		if got[i].Open != want[i].Open || got[i].High != want[i].High || got[i].Low != want[i].Low || got[i].Close != want[i].Close {
+			t.Errorf("bar %d = %v/%v/%v/%v, want %v/%v/%v/%v", i,
+				got[i].Open, got[i].High, got[i].Low, got[i].Close,
+				want[i].Open, want[i].High, want[i].Low, want[i].Close)
+		}
+	}
+}
+
+func TestHeikinAshiAggregatorSeed(t *testing.T) {
+	const id = "CON.F.US.EP.H24"
+	start := time.Date(2024, 1, 2, 14, 33, 0, 0, time.UTC)
+	history := []HistoryBar{
+		{Open: 10, High: 12, Low: 9, Close: 11},
+		{Open: 11, High: 14, Low: 10, Close: 13},
+		{Open: 13, High: 13.5, Low: 8, Close: 9},
+	}
+
+	// The live bar O9 H9.5 L8.5 C9 continues the seeded series
+	tests := []struct {
+		name string
+		seed []HistoryBar
+		want HistoryBar
+	}{
+		{"seeded", history, HistoryBar{Open: 11.0625, High: 11.0625, Low: 8.5, Close: 9, Vol: 4}},
+		{"unseeded", nil, HistoryBar{Open: 9, High: 9.5, Low: 8.5, Close: 9, Vol: 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			clock := &testClock{now: start}
+			var bars []HistoryBar
+			a := NewHeikinAshiAggregator(id, 1, func(bar HistoryBar) { bars = append(bars, bar) }).WithClock(clock.Now)
+			a.Seed(tt.seed)
+
+			for i, price := range []float64{9, 9.5, 8.5, 9} {
+				clock.now = start.Add(time.Duration(i) * 10 * time.Second)
+				a.OnTrade(id, trade(price, 1, OrderSideBidBuy))
+			}
+			a.Flush()
+
+			if len(bars) != 1 {
+				t.Fatalf("got %d bars, want 1", len(bars))
+			}
+			got := bars[0]
+			if got.Open != tt.want.Open || got.High != tt.want.High || got.Low != tt.want.Low || got.Close != tt.want.Close || got.Vol != tt.want.Vol {
+				t.Errorf("got %v/%v/%v/%v vol %d, want %v/%v/%v/%v vol %d",
+					got.Open, got.High, got.Low, got.Close, got.Vol,
+					tt.want.Open, tt.want.High, tt.want.Low, tt.want.Close, tt.want.Vol)
+			}
+			if prev, ok := a.PreviousBar(); !ok || prev != got {
+				t.Errorf("previous bar %+v, want the emitted bar", prev)
+			}
+		})
+	}
+}
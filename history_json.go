@@ -0,0 +1,75 @@
+package projectx
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+)
+
+// historyBarJSON is the wire form of a HistoryBar. The volume is decoded leniently
+// because some feeds send it as a float, as a string or not at all.
+type historyBarJSON struct {
+	Time  time.Time  `json:"t"`
+	Open  float64    `json:"o"`
+	High  float64    `json:"h"`
+	Low   float64    `json:"l"`
+	Close float64    `json:"c"`
+	Vol   wireVolume `json:"v"`
+
+	ContractID string `json:"contractId,omitempty"`
+}
+
+func (b historyBarJSON) bar() HistoryBar {
+	return HistoryBar{
+		Time:  b.Time,
+		Open:  b.Open,
+		High:  b.High,
+		Low:   b.Low,
+		Close: b.Close,
+		Vol:   int(b.Vol),
+
+		ContractID: b.ContractID,
+	}
+}
+
+// wireVolume decodes a bar volume sent as an integer, a float rounded to the nearest
+// whole contract, or a numeric string. Null decodes to zero.
+type wireVolume int
+
+func (v *wireVolume) UnmarshalJSON(data []byte) error {
+	data = bytes.Trim(data, `"`)
+	if len(data) == 0 || string(data) == "null" {
+		*v = 0
+		return nil
+	}
+	f, err := strconv.ParseFloat(string(data), 64)
+	if err != nil || math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("invalid bar volume %s", data)
+	}
+	*v = wireVolume(math.Round(f))
+	return nil
+}
+
+// UnmarshalJSON decodes the bars through historyBarJSON. It is defined on the
+// response rather than on HistoryBar, whose method DeltaBar would otherwise inherit.
+func (r *HistoryResponse) UnmarshalJSON(data []byte) error {
+	var wire struct {
+		Bars []historyBarJSON `json:"bars"`
+		BaseResponse
+	}
+	if err := json.Unmarshal(data, &wire); err != nil {
+		return err
+	}
+	r.BaseResponse = wire.BaseResponse
+	r.Bars = nil
+	if wire.Bars != nil {
+		r.Bars = make([]HistoryBar, len(wire.Bars))
+		for i, b := range wire.Bars {
+			r.Bars[i] = b.bar()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,82 @@
+package projectx
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHistoryResponseVolume(t *testing.T) {
+	tests := []struct {
+		name    string
+		volume  string // JSON of the "v" member, empty to leave it out
+		want    int
+		wantErr bool
+	}{
+		{"integer", `1200`, 1200, false},
+		{"float", `1200.0`, 1200, false},
+		{"fractional float", `1199.6`, 1200, false},
+		{"string", `"1200"`, 1200, false},
+		{"float string", `"1200.4"`, 1200, false},
+		{"null", `null`, 0, false},
+		{"empty string", `""`, 0, false},
+		{"missing", ``, 0, false},
+		{"non-numeric string", `"n/a"`, 0, true},
+		{"boolean", `true`, 0, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bar := `{"t":"2024-01-02T14:30:00Z","o":4700.25,"h":4701,"l":4699.5,"c":4700.75`
+			if tt.volume != "" {
+				bar += `,"v":` + tt.volume
+			}
+			bar += `}`
+			body := `{"success":true,"errorCode":0,"errorMessage":null,"bars":[` + bar + `]}`
+
+			var resp HistoryResponse
+			err := json.Unmarshal([]byte(body), &resp)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("decoded volume %s as %d, want an error", tt.volume, resp.Bars[0].Vol)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !resp.Success || len(resp.Bars) != 1 {
+				t.Fatalf("decoded %+v", resp)
+			}
+			got := resp.Bars[0]
+			want := HistoryBar{Time: time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC), Open: 4700.25, High: 4701, Low: 4699.5, Close: 4700.75, Vol: tt.want}
+			if got != want {
+				t.Errorf("decoded %+v, want %+v", got, want)
+			}
+
+			// The streaming decoder reads the same wire form
+			var streamed []HistoryBar
+			err = streamHistoryBars(context.Background(), json.NewDecoder(strings.NewReader(body)), "CON.F.US.EP.H24", &BaseResponse{}, func(b HistoryBar) error {
+				streamed = append(streamed, b)
+				return nil
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(streamed) != 1 || streamed[0].Vol != tt.want {
+				t.Errorf("streamed %+v, want volume %d", streamed, tt.want)
+			}
+		})
+	}
+}
+
+func TestHistoryResponseNullBars(t *testing.T) {
+	var resp HistoryResponse
+	if err := json.Unmarshal([]byte(`{"success":false,"errorCode":1,"errorMessage":"no data","bars":null}`), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Bars != nil || resp.Success || resp.ErrorCode != 1 || resp.ErrorMessage != "no data" {
+		t.Errorf("decoded %+v", resp)
+	}
+}
@@ -0,0 +1,97 @@
+package projectx
+
+import (
+	"context"
+	"sort"
+	"time"
+)
+
+// historyPageLimit is the page size used when HistoryRequest.Limit is zero; it is the
+// gateway's maximum number of bars per request.
+const historyPageLimit = 20000
+
+// HistoryCursor records how far a paginated history fetch has got. Pages are fetched
+// newest first, so the cursor holds the time of the oldest bar delivered. It marshals to
+// JSON, so it can be persisted after every page and passed back to resume an
+// interrupted backfill. The zero cursor starts at EndTime.
+type HistoryCursor struct {
+	Oldest time.Time `json:"oldest"`
+}
+
+// IsZero reports whether the cursor is at the end of the request window.
+func (c HistoryCursor) IsZero() bool {
+	return c.Oldest.IsZero()
+}
+
+// GetHistoricalBarsPaged fetches the window of req in pages of req.Limit bars (20000 when
+// zero), starting before cursor. The gateway returns the newest bars of a window that
+// holds more than the limit, so pages are fetched backward from EndTime to StartTime:
+// each page ends at the oldest bar of the previous one. onPage receives each page sorted
+// oldest first, newest page first, together with the cursor to resume from once the
+// page is safely stored; returning an error from it stops the fetch. The final cursor is
+// returned, also on error, so the caller can resume where it stopped. A page shorter
+// than the limit ends the fetch.
+func (c *Client) GetHistoricalBarsPaged(ctx context.Context, req HistoryRequest, cursor HistoryCursor, onPage func(bars []HistoryBar, next HistoryCursor) error) (HistoryCursor, error) {
+	if req.Limit == 0 {
+		req.Limit = historyPageLimit
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return cursor, err
+		}
+
+		page := req
+		if !cursor.IsZero() {
+			page.EndTime = cursor.Oldest
+		}
+		if !page.StartTime.Before(page.EndTime) {
+			return cursor, nil
+		}
+
+		bars, err := c.GetHistoricalBars(page)
+		if err != nil {
+			return cursor, err
+		}
+		received := len(bars)
+
+		// Pages overlap at the cursor, and the gateway may return bars newest first
+		sort.Slice(bars, func(i, j int) bool { return bars[i].Time.Before(bars[j].Time) })
+		if !cursor.IsZero() {
+			i := sort.Search(len(bars), func(i int) bool { return !bars[i].Time.Before(cursor.Oldest) })
+			bars = bars[:i]
+		}
+		if len(bars) == 0 {
+			return cursor, nil
+		}
+
+		next := HistoryCursor{Oldest: bars[0].Time}
+		if err := onPage(bars, next); err != nil {
+			return cursor, err
+		}
+		cursor = next
+
+		if received < req.Limit {
+			return cursor, nil
+		}
+	}
+}
+
+// GetAllHistoricalBars fetches the whole window of req, paginating as needed, and
+// returns the bars oldest first with the final cursor. On error the bars fetched so far
+// are returned with the cursor to resume from.
+func (c *Client) GetAllHistoricalBars(ctx context.Context, req HistoryRequest, cursor HistoryCursor) ([]HistoryBar, HistoryCursor, error) {
+	var pages [][]HistoryBar
+	total := 0
+	cursor, err := c.GetHistoricalBarsPaged(ctx, req, cursor, func(bars []HistoryBar, next HistoryCursor) error {
+		pages = append(pages, bars)
+		total += len(bars)
+		return nil
+	})
+
+	all := make([]HistoryBar, 0, total)
+	for i := len(pages) - 1; i >= 0; i-- {
+		all = append(all, pages[i]...)
+	}
+	return all, cursor, err
+}
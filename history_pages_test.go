@@ -0,0 +1,93 @@
+package projectx
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// newestBarsServer serves one-minute bars from start, answering each history request
+// with the newest req.Limit bars of its window, newest first, as the gateway does.
+func newestBarsServer(t *testing.T, start time.Time, count int) *httptest.Server {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req HistoryRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		var bars []HistoryBar
+		for i := count - 1; i >= 0 && len(bars) < req.Limit; i-- {
+			bar := HistoryBar{Time: start.Add(time.Duration(i) * time.Minute), Close: float64(i)}
+			if !bar.Time.Before(req.StartTime) && !bar.Time.After(req.EndTime) {
+				bars = append(bars, bar)
+			}
+		}
+		json.NewEncoder(w).Encode(map[string]any{"success": true, "bars": bars})
+	}))
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestGetAllHistoricalBarsNewestPages(t *testing.T) {
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	srv := newestBarsServer(t, start, 25)
+	c := NewClient(srv.URL)
+
+	req := HistoryRequest{
+		ContractID: "CON.F.US.EP.H24",
+		StartTime:  start,
+		EndTime:    start.Add(24 * time.Minute),
+		Unit:       TimeUnitMinute,
+		UnitNumber: 1,
+		Limit:      10,
+	}
+	bars, cursor, err := c.GetAllHistoricalBars(context.Background(), req, HistoryCursor{})
+	if err != nil {
+		t.Fatalf("GetAllHistoricalBars: %v", err)
+	}
+	if len(bars) != 25 {
+		t.Fatalf("got %d bars, want 25", len(bars))
+	}
+	for i, bar := range bars {
+		if want := start.Add(time.Duration(i) * time.Minute); !bar.Time.Equal(want) {
+			t.Fatalf("bar %d at %s, want %s", i, bar.Time, want)
+		}
+	}
+	if !cursor.Oldest.Equal(start) {
+		t.Errorf("cursor at %s, want %s", cursor.Oldest, start)
+	}
+}
+
+func TestGetHistoricalBarsPagedResume(t *testing.T) {
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	srv := newestBarsServer(t, start, 25)
+	c := NewClient(srv.URL)
+
+	req := HistoryRequest{StartTime: start, EndTime: start.Add(24 * time.Minute), Unit: TimeUnitMinute, UnitNumber: 1, Limit: 10}
+	stop := context.Canceled
+	pages := 0
+	cursor, err := c.GetHistoricalBarsPaged(context.Background(), req, HistoryCursor{}, func(bars []HistoryBar, next HistoryCursor) error {
+		pages++
+		if pages == 2 {
+			return stop
+		}
+		return nil
+	})
+	if err != stop {
+		t.Fatalf("got error %v, want %v", err, stop)
+	}
+	if want := start.Add(15 * time.Minute); !cursor.Oldest.Equal(want) {
+		t.Fatalf("cursor at %s, want %s", cursor.Oldest, want)
+	}
+
+	bars, _, err := c.GetAllHistoricalBars(context.Background(), req, cursor)
+	if err != nil {
+		t.Fatalf("resume: %v", err)
+	}
+	if len(bars) != 15 || !bars[0].Time.Equal(start) || !bars[14].Time.Equal(cursor.Oldest.Add(-time.Minute)) {
+		t.Fatalf("resumed %d bars from %s, want 15 before the cursor", len(bars), bars[0].Time)
+	}
+}
@@ -0,0 +1,123 @@
+package projectx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// GetHistoricalBarsStream is GetHistoricalBars for large pulls: bars are decoded one at a
+// time from the response body and passed to fn instead of being collected into a slice,
+// so memory stays flat regardless of the number of bars. Bars arrive in the order the
+// gateway sends them. Returning an error from fn, or cancelling ctx, stops the stream.
+func (c *Client) GetHistoricalBarsStream(ctx context.Context, req HistoryRequest, fn func(bar HistoryBar) error) error {
+	const endpoint = "/api/history/retrieveBars"
+	if err := req.Validate(); err != nil {
+		return fmt.Errorf("historical data request failed: %w", err)
+	}
+
+	var env BaseResponse
+	decode := func(r io.Reader) error {
+		env = BaseResponse{}
+		return wrapDecodeError(streamHistoryBars(ctx, json.NewDecoder(r), req.ContractID, &env, fn))
+	}
+	if err := c.sendContext(ctx, "POST", endpoint, req, decode, true); err != nil {
+		return err
+	}
+	return env.AsError(endpoint)
+}
+
+// streamHistoryBars walks the top-level response object, streaming the "bars" array
+// element by element and decoding the status fields into env.
+func streamHistoryBars(ctx context.Context, dec *json.Decoder, contractID string, env *BaseResponse, fn func(HistoryBar) error) error {
+	if err := expectDelim(dec, '{'); err != nil {
+		return err
+	}
+
+	for dec.More() {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+		key, _ := tok.(string)
+
+		switch key {
+		case "bars":
+			if err := streamBarsArray(ctx, dec, contractID, fn); err != nil {
+				return err
+			}
+		case "success":
+			if err := dec.Decode(&env.Success); err != nil {
+				return err
+			}
+		case "errorCode":
+			if err := dec.Decode(&env.ErrorCode); err != nil {
+				return err
+			}
+		case "errorMessage":
+			if err := dec.Decode(&env.ErrorMessage); err != nil {
+				return err
+			}
+		case "validationErrors":
+			if err := dec.Decode(&env.ValidationErrors); err != nil {
+				return err
+			}
+		case "errors":
+			if err := dec.Decode(&env.Errors); err != nil {
+				return err
+			}
+		default:
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return err
+			}
+		}
+	}
+
+	return expectDelim(dec, '}')
+}
+
+func streamBarsArray(ctx context.Context, dec *json.Decoder, contractID string, fn func(HistoryBar) error) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+	if tok == nil {
+		return nil // "bars": null
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != '[' {
+		return fmt.Errorf("unexpected token %v, want bars array", tok)
+	}
+
+	for dec.More() {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		var wire historyBarJSON
+		if err := dec.Decode(&wire); err != nil {
+			return err
+		}
+		bar := wire.bar()
+		bar.ContractID = contractID
+		if err := fn(bar); err != nil {
+			return err
+		}
+	}
+
+	return expectDelim(dec, ']')
+}
+
+func expectDelim(dec *json.Decoder, want json.Delim) error {
+	tok, err := dec.Token()
+	if err != nil {
+		if err == io.EOF {
+			return io.ErrUnexpectedEOF
+		}
+		return err
+	}
+	if delim, ok := tok.(json.Delim); !ok || delim != want {
+		return fmt.Errorf("unexpected token %v, want %v", tok, want)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package projectx
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestGetHistoricalBarsStreamFieldErrors(t *testing.T) {
+	bodies := map[string]string{
+		"validationErrors": `{"bars":null,"success":false,"errorCode":0,"errorMessage":null,` +
+			`"validationErrors":[{"propertyName":"unitNumber","errorMessage":"must be positive"}]}`,
+		"errors": `{"success":false,"errorCode":0,"errors":{"unitNumber":["must be positive"]}}`,
+	}
+	for name, body := range bodies {
+		t.Run(name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Write([]byte(body))
+			}))
+			defer srv.Close()
+
+			start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+			req := HistoryRequest{StartTime: start, EndTime: start.Add(time.Hour), Unit: TimeUnitMinute, UnitNumber: 1, Limit: 10}
+			err := NewClient(srv.URL).GetHistoricalBarsStream(context.Background(), req, func(HistoryBar) error { return nil })
+
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) {
+				t.Fatalf("got error %v, want an APIError", err)
+			}
+			want := FieldError{Field: "unitNumber", Message: "must be positive"}
+			if len(apiErr.FieldErrors) != 1 || apiErr.FieldErrors[0] != want {
+				t.Errorf("field errors %v, want [%v]", apiErr.FieldErrors, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package projectx
+
+import (
+	"errors"
+	"fmt"
+)
+
+// MarginSchedule maps a contract ID or symbol ID to the initial margin per contract,
+// keyed like FeeSchedule. The gateway has no margin endpoint, so margin previews are
+// estimated from this schedule.
+type MarginSchedule map[string]float64
+
+// MarginFor returns the initial margin per contract. The contract ID takes precedence
+// over the symbol ID. The second result is false when neither is listed.
+func (s MarginSchedule) MarginFor(contract Contract) (float64, bool) {
+	return scheduleLookup(s, contract)
+}
+
+// MarginPreview is the estimated margin impact of an order, assuming it fills in full.
+type MarginPreview struct {
+	RequiredMargin  float64 // Margin for the order's contracts on their own
+	CurrentMargin   float64 // Margin held by the account's open positions
+	ResultingMargin float64 // Margin held after the order, netted against an opposite position
+	Balance         float64 // Account balance
+	BuyingPower     float64 // Balance less ResultingMargin; negative when the order cannot be afforded
+}
+
+// WithMarginSchedule sets the initial margins used by PreviewOrderMargin.
+func WithMarginSchedule(schedule MarginSchedule) ClientOption {
+	return func(c *Client) {
+		c.margins = schedule
+	}
+}
+
+// PreviewOrderMargin estimates the margin impact of an order from the account balance,
+// its open positions and the client's MarginSchedule. The gateway offers no margin
+// preview, so this is always a client-side estimate; every contract involved must be in
+// the schedule.
+func (c *Client) PreviewOrderMargin(order OrderRequest) (*MarginPreview, error) {
+	if c.margins == nil {
+		return nil, errors.New("margin preview failed: no margin schedule configured")
+	}
+	account, err := c.GetAccount(order.AccountID)
+	if err != nil {
+		return nil, err
+	}
+	positions, err := c.GetOpenPositions(order.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	marginFor := func(contractID string) (float64, error) {
+		contract, err := c.GetContractByIDCached(contractID)
+		if err != nil {
+			return 0, err
+		}
+		margin, ok := c.margins.MarginFor(*contract)
+		if !ok {
+			return 0, fmt.Errorf("margin preview failed: no initial margin configured for %s", contractID)
+		}
+		return margin, nil
+	}
+
+	orderMargin, err := marginFor(order.ContractID)
+	if err != nil {
+		return nil, err
+	}
+
+	preview := &MarginPreview{
+		RequiredMargin: orderMargin * float64(order.Size),
+		Balance:        account.Balance,
+	}
+	net := order.Side.Sign() * order.Size
+	for _, p := range positions {
+		margin, err := marginFor(p.ContractID)
+		if err != nil {
+			return nil, err
+		}
+		preview.CurrentMargin += margin * float64(p.Size)
+		if p.ContractID == order.ContractID {
+			net += p.SignedSize()
+			continue
+		}
+		preview.ResultingMargin += margin * float64(p.Size)
+	}
+	if net < 0 {
+		net = -net
+	}
+	preview.ResultingMargin += orderMargin * float64(net)
+	preview.BuyingPower = preview.Balance - preview.ResultingMargin
+	return preview, nil
+}
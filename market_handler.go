@@ -1,6 +1,7 @@
 package projectx
 
 import (
+	"fmt"
 	"log"
 	"sync"
 	"time"
@@ -8,21 +9,273 @@ import (
 
 type MarketDataCallback func(bar HistoryBar)
 
+// DeltaBar is a bar with its volume split by trade aggressor side, for order-flow
+// analysis. Trades whose side the feed does not report count toward Vol only.
+//
+// Seq numbers the bars emitted by one manager 1, 2, 3, ... in close order, so a
+// consumer can detect a dropped or reordered bar as a gap or step back. Numbering
+// restarts at 1 with every new manager, e.g. after a process restart; use
+// WithSequenceStart to continue a persisted sequence.
+type DeltaBar struct {
+	HistoryBar
+	BuyVolume  int
+	SellVolume int
+	Seq        uint64
+}
+
+// Delta returns buy volume minus sell volume.
+func (b DeltaBar) Delta() int {
+	return b.BuyVolume - b.SellVolume
+}
+
+// DeltaBarCallback receives completed bars with side volume.
+type DeltaBarCallback func(bar DeltaBar)
+
+// EnrichedBar extends DeltaBar with statistics of the bar's trades. VWAP, Trades and
+// the side volumes are computed from trade messages only, so they stay zero when just
+// quotes are subscribed; OHLC are also updated from quote mids. VWAP is zero for a
+// bar without traded volume.
+type EnrichedBar struct {
+	DeltaBar
+	VWAP   float64 // Volume-weighted average trade price
+	Trades int     // Number of trades
+}
+
+// EnrichedBarCallback receives completed bars with trade statistics.
+type EnrichedBarCallback func(bar EnrichedBar)
+
+// MarketDataErrorCallback is a bar callback that can report failure, e.g. a
+// database sink that could not persist the bar. The manager logs and counts
+// returned errors and keeps building bars; see CallbackErrors.
+type MarketDataErrorCallback func(bar HistoryBar) error
+
+// ToErrorCallback adapts a MarketDataCallback to a MarketDataErrorCallback that never fails.
+func (cb MarketDataCallback) ToErrorCallback() MarketDataErrorCallback {
+	if cb == nil {
+		return nil
+	}
+	return func(bar HistoryBar) error {
+		cb(bar)
+		return nil
+	}
+}
+
+// BarAggregator builds bars from streaming market data. It receives data through the
+// MarketDataHandler methods, so any aggregator can be handed to NewSignalRClient, and
+// emits each completed bar to a callback supplied at construction.
+//
+// Implementations must ignore data for contracts other than ContractID, be safe for
+// concurrent use, and emit every bar exactly once in time order. MarketDataManager
+// builds plain time bars; HeikinAshiAggregator is an example of deriving a different
+// bar type on top of it.
+//
+// Flush emits the bar still forming, if any, and starts the next bar afresh with the
+// following update. SignalRClient.Unsubscribe calls it when the aggregator is the
+// client's handler and the unsubscribed contract is its ContractID, so the final bar
+// before an instrument is rotated out is not lost.
+type BarAggregator interface {
+	MarketDataHandler
+	ContractID() string
+	Flush()
+}
+
+var (
+	_ BarAggregator = (*MarketDataManager)(nil)
+	_ BarAggregator = (*HeikinAshiAggregator)(nil)
+)
+
 type MarketDataManager struct {
-	mutex         sync.RWMutex
-	currentBar    *HistoryBar
-	lastTradeTime time.Time
-	barPeriod     time.Duration
-	callback      MarketDataCallback
-	contractID    string
+	mutex            sync.RWMutex
+	currentBar       *HistoryBar
+	lastTradeTime    time.Time
+	barPeriod        time.Duration
+	callback         MarketDataErrorCallback
+	deltaCallback    DeltaBarCallback
+	buyVolume        int
+	sellVolume       int
+	callbackErrors   int
+	lastCallbackErr  error
+	contractID       string
+	quotePriceMode   QuotePriceMode
+	clock            func() time.Time
+	seq              uint64
+	notional         float64
+	trades           int
+	enrichedCallback EnrichedBarCallback
+	session          *SessionStats // Session extremes of contractID, see WithSessionStats
+	sharedSession    bool          // Whether session was supplied by WithSessionStats
 }
 
 func NewMarketDataManager(contractID string, barPeriodMinutes int, callback MarketDataCallback) *MarketDataManager {
+	return NewMarketDataManagerWithErrors(contractID, barPeriodMinutes, callback.ToErrorCallback())
+}
+
+func NewMarketDataManagerWithErrors(contractID string, barPeriodMinutes int, callback MarketDataErrorCallback) *MarketDataManager {
+	return newMarketDataManager(contractID, time.Duration(barPeriodMinutes)*time.Minute, callback)
+}
+
+// NewMarketDataManagerDuration creates a manager building bars of any positive length,
+// e.g. 15*time.Second for sub-minute bars.
+func NewMarketDataManagerDuration(contractID string, period time.Duration, callback MarketDataCallback) (*MarketDataManager, error) {
+	if period <= 0 {
+		return nil, fmt.Errorf("invalid bar period %s: must be positive", period)
+	}
+	return newMarketDataManager(contractID, period, callback.ToErrorCallback()), nil
+}
+
+func newMarketDataManager(contractID string, period time.Duration, callback MarketDataErrorCallback) *MarketDataManager {
 	return &MarketDataManager{
-		barPeriod:  time.Duration(barPeriodMinutes) * time.Minute,
+		barPeriod:  period,
 		callback:   callback,
 		contractID: contractID,
+		session:    NewSessionStats(nil, 0),
+	}
+}
+
+// WithQuotePriceMode sets the price quotes contribute to bars. The default is QuoteMid.
+func (m *MarketDataManager) WithQuotePriceMode(mode QuotePriceMode) *MarketDataManager {
+	m.mutex.Lock()
+	m.quotePriceMode = mode
+	m.mutex.Unlock()
+	return m
+}
+
+// WithClock sets the time source used to stamp and close bars, e.g. ServerClock to
+// align bars with the gateway rather than a drifting local clock. Nil restores time.Now.
+// It also clocks the session extremes unless a tracker was set with WithSessionStats.
+func (m *MarketDataManager) WithClock(clock func() time.Time) *MarketDataManager {
+	m.mutex.Lock()
+	m.clock = clock
+	if !m.sharedSession {
+		m.session.WithClock(clock)
+	}
+	m.mutex.Unlock()
+	return m
+}
+
+// now returns the current time from the configured clock. Callers hold the lock.
+func (m *MarketDataManager) now() time.Time {
+	if m.clock != nil {
+		return m.clock()
+	}
+	return time.Now()
+}
+
+// WithSequenceStart sets the sequence number of the next emitted bar, e.g. one past
+// the last Seq persisted before a restart. The default is 1.
+func (m *MarketDataManager) WithSequenceStart(next uint64) *MarketDataManager {
+	m.mutex.Lock()
+	m.seq = next - 1
+	m.mutex.Unlock()
+	return m
+}
+
+// WithSessionStats sets the tracker behind SessionHigh and SessionLow, e.g. one created
+// with a 17:00 America/Chicago session start for CME Globex and shared with other
+// handlers. The manager feeds it the data of its contract; the tracker keeps its own
+// clock. Nil restores the default tracker, whose sessions start at midnight UTC.
+func (m *MarketDataManager) WithSessionStats(stats *SessionStats) *MarketDataManager {
+	m.mutex.Lock()
+	m.sharedSession = stats != nil
+	if stats == nil {
+		stats = NewSessionStats(nil, 0).WithClock(m.clock)
+	}
+	m.session = stats
+	m.mutex.Unlock()
+	return m
+}
+
+// WithDeltaCallback registers a callback that receives each completed bar with its
+// buy and sell volume, in addition to the regular bar callback.
+func (m *MarketDataManager) WithDeltaCallback(callback DeltaBarCallback) *MarketDataManager {
+	m.mutex.Lock()
+	m.deltaCallback = callback
+	m.mutex.Unlock()
+	return m
+}
+
+// WithEnrichedCallback registers a callback that receives each completed bar with VWAP
+// and trade count, in addition to the other callbacks.
+func (m *MarketDataManager) WithEnrichedCallback(callback EnrichedBarCallback) *MarketDataManager {
+	m.mutex.Lock()
+	m.enrichedCallback = callback
+	m.mutex.Unlock()
+	return m
+}
+
+// ContractID returns the contract whose data the manager aggregates.
+func (m *MarketDataManager) ContractID() string {
+	return m.contractID
+}
+
+// BarPeriod returns the length of the bars built by the manager.
+func (m *MarketDataManager) BarPeriod() time.Duration {
+	return m.barPeriod
+}
+
+// CallbackErrors returns how many bar callbacks have failed and the most recent error.
+func (m *MarketDataManager) CallbackErrors() (int, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.callbackErrors, m.lastCallbackErr
+}
+
+// Flush emits the bar being built, even though its period has not elapsed, and
+// discards it so the next update starts a new bar.
+func (m *MarketDataManager) Flush() {
+	m.mutex.Lock()
+	closed := m.closeCurrentBar()
+	m.currentBar = nil
+	m.mutex.Unlock()
+	m.dispatch(closed)
+}
+
+// Tick closes the bar being built if its period has elapsed by now, without waiting
+// for the next update, and starts the next bar afresh with the following update. Bars
+// otherwise close only when a quote or trade arrives; drive Tick from a timer, or from
+// SimClock.OnAdvance in backtests, so a quiet market does not hold a bar open.
+func (m *MarketDataManager) Tick(now time.Time) {
+	m.mutex.Lock()
+	var closed *closedBar
+	if m.currentBar != nil && now.Sub(m.currentBar.Time) >= m.barPeriod {
+		closed = m.closeCurrentBar()
+		m.currentBar = nil
+	}
+	m.mutex.Unlock()
+	m.dispatch(closed)
+}
+
+// SessionHigh returns the highest price of the current session across all bars, or
+// false before the first price of the session. Session extremes follow SessionStats:
+// they come from trade prices and the quotes' last price, not the quote prices that
+// build bars.
+func (m *MarketDataManager) SessionHigh() (float64, bool) {
+	st, ok := m.sessionStats().Current(m.contractID)
+	return st.High, ok
+}
+
+// SessionLow returns the lowest price of the current session across all bars, or false
+// before the first price of the session, see SessionHigh.
+func (m *MarketDataManager) SessionLow() (float64, bool) {
+	st, ok := m.sessionStats().Current(m.contractID)
+	return st.Low, ok
+}
+
+// sessionStats returns the tracker of the session extremes.
+func (m *MarketDataManager) sessionStats() *SessionStats {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	return m.session
+}
+
+// CurrentBar returns a copy of the bar being built, or false before the first update.
+func (m *MarketDataManager) CurrentBar() (HistoryBar, bool) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+	if m.currentBar == nil {
+		return HistoryBar{}, false
 	}
+	return *m.currentBar, true
 }
 
 func (m *MarketDataManager) OnQuote(contractID string, data map[string]interface{}) {
@@ -30,27 +283,39 @@ func (m *MarketDataManager) OnQuote(contractID string, data map[string]interface
 		return
 	}
 
+	m.sessionStats().OnQuote(contractID, data)
+
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	closed := m.applyQuote(data)
+	m.mutex.Unlock()
+	m.dispatch(closed)
+}
 
+// applyQuote updates the current bar from a quote. It must be called with the lock held
+// and returns the bar it closed, if any, for dispatch after the lock is released.
+func (m *MarketDataManager) applyQuote(data map[string]interface{}) *closedBar {
 	// Extract quote data
 	bid, ok1 := data["bid"].(float64)
 	ask, ok2 := data["ask"].(float64)
 	if !ok1 || !ok2 {
 		log.Printf("Invalid quote data format")
-		return
+		return nil
 	}
 
-	now := time.Now()
+	now := m.now()
+
+	price := (bid + ask) / 2
+	if m.quotePriceMode != QuoteMid {
+		price = ParseQuote(m.contractID, data).Mid(m.quotePriceMode)
+	}
 
 	// Initialize or update current bar
 	if m.currentBar == nil {
-		m.initializeNewBar(now, (bid+ask)/2)
-		return
+		m.initializeNewBar(now, price)
+		return nil
 	}
 
 	// Update current bar
-	price := (bid + ask) / 2
 	if price > m.currentBar.High {
 		m.currentBar.High = price
 	}
@@ -61,9 +326,11 @@ func (m *MarketDataManager) OnQuote(contractID string, data map[string]interface
 
 	// Check if it's time to close the bar
 	if now.Sub(m.currentBar.Time) >= m.barPeriod {
-		m.closeCurrentBar()
+		closed := m.closeCurrentBar()
 		m.initializeNewBar(now, price)
+		return closed
 	}
+	return nil
 }
 
 func (m *MarketDataManager) OnTrade(contractID string, data map[string]interface{}) {
@@ -71,27 +338,39 @@ func (m *MarketDataManager) OnTrade(contractID string, data map[string]interface
 		return
 	}
 
+	m.sessionStats().OnTrade(contractID, data)
+
 	m.mutex.Lock()
-	defer m.mutex.Unlock()
+	closed := m.applyTrade(contractID, data)
+	m.mutex.Unlock()
+	m.dispatch(closed)
+}
 
+// applyTrade updates the current bar from a trade. It must be called with the lock held
+// and returns the bar it closed, if any, for dispatch after the lock is released.
+func (m *MarketDataManager) applyTrade(contractID string, data map[string]interface{}) *closedBar {
 	// Extract trade data
 	price, ok1 := data["price"].(float64)
 	size, ok2 := data["size"].(float64)
 	if !ok1 || !ok2 {
 		log.Printf("Invalid trade data format")
-		return
+		return nil
 	}
 
-	now := time.Now()
+	now := m.now()
 	m.lastTradeTime = now
 
+	// A trade past the bar period closes the bar and belongs to the next one
+	var closed *closedBar
+	if m.currentBar != nil && now.Sub(m.currentBar.Time) >= m.barPeriod {
+		closed = m.closeCurrentBar()
+		m.currentBar = nil
+	}
+
 	// Initialize or update current bar
 	if m.currentBar == nil {
 		m.initializeNewBar(now, price)
-		return
 	}
-
-	// Update current bar
 	if price > m.currentBar.High {
 		m.currentBar.High = price
 	}
@@ -100,12 +379,15 @@ func (m *MarketDataManager) OnTrade(contractID string, data map[string]interface
 	}
 	m.currentBar.Close = price
 	m.currentBar.Vol += int(size)
-
-	// Check if it's time to close the bar
-	if now.Sub(m.currentBar.Time) >= m.barPeriod {
-		m.closeCurrentBar()
-		m.initializeNewBar(now, price)
+	m.notional += price * float64(int(size))
+	m.trades++
+	switch ParseMarketTrade(contractID, data).Side {
+	case OrderSideBidBuy:
+		m.buyVolume += int(size)
+	case OrderSideAskSell:
+		m.sellVolume += int(size)
 	}
+	return closed
 }
 
 func (m *MarketDataManager) OnDepth(contractID string, data map[string]interface{}) {
@@ -115,17 +397,74 @@ func (m *MarketDataManager) OnDepth(contractID string, data map[string]interface
 func (m *MarketDataManager) initializeNewBar(t time.Time, price float64) {
 	barStartTime := t.Truncate(m.barPeriod)
 	m.currentBar = &HistoryBar{
-		Time:  barStartTime,
-		Open:  price,
-		High:  price,
-		Low:   price,
-		Close: price,
-		Vol:   0,
+		Time:       barStartTime,
+		Open:       price,
+		High:       price,
+		Low:        price,
+		Close:      price,
+		Vol:        0,
+		ContractID: m.contractID,
 	}
+	m.buyVolume = 0
+	m.sellVolume = 0
+	m.notional = 0
+	m.trades = 0
 }
 
-func (m *MarketDataManager) closeCurrentBar() {
-	if m.currentBar != nil && m.callback != nil {
-		m.callback(*m.currentBar)
+// closedBar is a completed bar with the callbacks to notify, captured under the lock.
+type closedBar struct {
+	bar              EnrichedBar
+	callback         MarketDataErrorCallback
+	deltaCallback    DeltaBarCallback
+	enrichedCallback EnrichedBarCallback
+}
+
+// closeCurrentBar captures the current bar for dispatch. It must be called with the lock held.
+func (m *MarketDataManager) closeCurrentBar() *closedBar {
+	if m.currentBar == nil {
+		return nil
+	}
+	m.seq++
+	bar := EnrichedBar{
+		DeltaBar: DeltaBar{
+			HistoryBar: *m.currentBar,
+			BuyVolume:  m.buyVolume,
+			SellVolume: m.sellVolume,
+			Seq:        m.seq,
+		},
+		Trades: m.trades,
+	}
+	if m.currentBar.Vol > 0 {
+		bar.VWAP = m.notional / float64(m.currentBar.Vol)
+	}
+	return &closedBar{
+		bar:              bar,
+		callback:         m.callback,
+		deltaCallback:    m.deltaCallback,
+		enrichedCallback: m.enrichedCallback,
+	}
+}
+
+// dispatch delivers a closed bar to the callbacks. It must be called without the lock
+// held, so callbacks may call back into the manager (e.g. CurrentBar) and a slow
+// callback does not block readers.
+func (m *MarketDataManager) dispatch(closed *closedBar) {
+	if closed == nil {
+		return
+	}
+	if closed.callback != nil {
+		if err := closed.callback(closed.bar.HistoryBar); err != nil {
+			m.mutex.Lock()
+			m.callbackErrors++
+			m.lastCallbackErr = err
+			m.mutex.Unlock()
+			log.Printf("Bar callback failed for %s: %v", m.contractID, err)
+		}
+	}
+	if closed.deltaCallback != nil {
+		closed.deltaCallback(closed.bar.DeltaBar)
+	}
+	if closed.enrichedCallback != nil {
+		closed.enrichedCallback(closed.bar)
 	}
 }
@@ -0,0 +1,157 @@
+package projectx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// testClock is a manually advanced clock for bar managers.
+type testClock struct {
+	now time.Time
+}
+
+func (c *testClock) Now() time.Time { return c.now }
+
+func trade(price, size float64, side int) map[string]interface{} {
+	return map[string]interface{}{"price": price, "size": size, "type": float64(side)}
+}
+
+func TestMarketDataManagerTradeStats(t *testing.T) {
+	const id = "CON.F.US.EP.H24"
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	clock := &testClock{now: start}
+
+	var bars []EnrichedBar
+	m := NewMarketDataManager(id, 1, nil).
+		WithClock(clock.Now).
+		WithEnrichedCallback(func(bar EnrichedBar) { bars = append(bars, bar) })
+
+	// First bar: the opening trade counts like any other
+	m.OnTrade(id, trade(100, 2, OrderSideBidBuy))
+	clock.now = start.Add(20 * time.Second)
+	m.OnTrade(id, trade(101, 1, OrderSideAskSell))
+	clock.now = start.Add(40 * time.Second)
+	m.OnTrade(id, trade(99, 3, OrderSideBidBuy))
+
+	// Second bar: opened by a trade, which counts toward it and not the first bar
+	clock.now = start.Add(time.Minute)
+	m.OnTrade(id, trade(102, 4, OrderSideAskSell))
+	clock.now = start.Add(90 * time.Second)
+	m.OnTrade(id, trade(104, 1, OrderSideBidBuy))
+
+	m.Flush()
+
+	want := []struct {
+		open, high, low, close float64
+		vol, trades, buy, sell int
+		vwap                   float64
+	}{
+		{100, 101, 99, 99, 6, 3, 5, 1, (100*2 + 101*1 + 99*3) / 6.0},
+		{102, 104, 102, 104, 5, 2, 1, 4, (102*4 + 104*1) / 5.0},
+	}
+	if len(bars) != len(want) {
+		t.Fatalf("got %d bars, want %d", len(bars), len(want))
+	}
+	for i, w := range want {
+		b := bars[i]
+		if !b.Time.Equal(start.Add(time.Duration(i) * time.Minute)) {
+			t.Errorf("bar %d at %s", i, b.Time)
+		}
+		if b.Open != w.open || b.High != w.high || b.Low != w.low || b.Close != w.close {
+			t.Errorf("bar %d OHLC %v/%v/%v/%v, want %v/%v/%v/%v", i, b.Open, b.High, b.Low, b.Close, w.open, w.high, w.low, w.close)
+		}
+		if b.Vol != w.vol || b.Trades != w.trades {
+			t.Errorf("bar %d volume %d in %d trades, want %d in %d", i, b.Vol, b.Trades, w.vol, w.trades)
+		}
+		if b.BuyVolume != w.buy || b.SellVolume != w.sell {
+			t.Errorf("bar %d buy/sell %d/%d, want %d/%d", i, b.BuyVolume, b.SellVolume, w.buy, w.sell)
+		}
+		if math.Abs(b.VWAP-w.vwap) > 1e-9 {
+			t.Errorf("bar %d VWAP %v, want %v", i, b.VWAP, w.vwap)
+		}
+	}
+}
+
+func TestMarketDataManagerSessionExtremes(t *testing.T) {
+	const id = "CON.F.US.EP.H24"
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		t.Skip(err)
+	}
+	start := time.Date(2024, 1, 2, 16, 58, 0, 0, chicago)
+	clock := &testClock{now: start}
+	stats := NewSessionStats(chicago, 17*time.Hour).WithClock(clock.Now)
+	m := NewMarketDataManager(id, 1, nil).WithClock(clock.Now).WithSessionStats(stats)
+
+	if _, ok := m.SessionHigh(); ok {
+		t.Fatal("session high before the first price")
+	}
+
+	// Extremes survive bar boundaries within a session
+	for i, price := range []float64{100, 103, 98, 101} {
+		clock.now = start.Add(time.Duration(i) * 30 * time.Second)
+		m.OnTrade(id, trade(price, 1, OrderSideBidBuy))
+	}
+	// A quote mid outside the range builds bars but is not a session price
+	m.OnQuote(id, map[string]interface{}{"bid": 110.0, "ask": 110.5})
+	if high, _ := m.SessionHigh(); high != 103 {
+		t.Errorf("session high %v, want 103", high)
+	}
+	if low, _ := m.SessionLow(); low != 98 {
+		t.Errorf("session low %v, want 98", low)
+	}
+	if st, _ := stats.Stats(id); st.High != 103 || st.Low != 98 {
+		t.Errorf("shared stats high/low %v/%v, want 103/98", st.High, st.Low)
+	}
+
+	// The 17:00 session start resets the extremes with its first price
+	clock.now = time.Date(2024, 1, 2, 17, 0, 0, 0, chicago)
+	if _, ok := m.SessionLow(); ok {
+		t.Error("previous session's low reported for the new session")
+	}
+	m.OnTrade(id, trade(102, 1, OrderSideAskSell))
+	high, _ := m.SessionHigh()
+	low, ok := m.SessionLow()
+	if !ok || high != 102 || low != 102 {
+		t.Errorf("new session high/low %v/%v (%t), want 102/102", high, low, ok)
+	}
+}
+
+// TestMarketDataManagerReentrantCallback calls back into the manager from its bar
+// callbacks, which must not deadlock since callbacks run without the lock held.
+func TestMarketDataManagerReentrantCallback(t *testing.T) {
+	const id = "CON.F.US.EP.H24"
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	clock := &testClock{now: start}
+
+	var m *MarketDataManager
+	var bars []HistoryBar
+	m = NewMarketDataManager(id, 1, func(bar HistoryBar) {
+		bars = append(bars, bar)
+		if len(bars) == 1 {
+			m.CurrentBar()
+			m.OnQuote(id, map[string]interface{}{"bid": 101.0, "ask": 101.5})
+			m.Flush()
+		}
+	}).WithClock(clock.Now)
+	m.WithEnrichedCallback(func(EnrichedBar) { m.CallbackErrors() })
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		m.OnTrade(id, trade(100, 1, OrderSideBidBuy))
+		clock.now = start.Add(time.Minute)
+		m.OnTrade(id, trade(102, 1, OrderSideBidBuy))
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("callback re-entering the manager deadlocked")
+	}
+
+	// The closed bar, then the bar the trade opened, flushed from the callback
+	if len(bars) != 2 || bars[0].Close != 100 || bars[1].Open != 102 || bars[1].Close != 101.25 {
+		t.Errorf("got bars %+v", bars)
+	}
+}
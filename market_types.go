@@ -0,0 +1,111 @@
+package projectx
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// TradeSideUnknown marks a trade whose aggressor side is not provided by the feed.
+const TradeSideUnknown Side = -1
+
+// Quote is a typed view of a quote message from the market hub.
+// Fields the feed does not send are left at their zero value.
+type Quote struct {
+	ContractID string
+	Bid        float64   // "bid"
+	Ask        float64   // "ask"
+	BidSize    int       // "bidSize"
+	AskSize    int       // "askSize"
+	Last       float64   // "lastPrice"
+	Timestamp  time.Time // "timestamp", RFC 3339
+}
+
+// MarketTrade is a typed view of a trade message from the market hub.
+type MarketTrade struct {
+	ContractID string
+	Price      float64   // "price"
+	Size       int       // "size"
+	Side       Side      // "type": aggressor side, or TradeSideUnknown
+	Timestamp  time.Time // "timestamp", RFC 3339
+}
+
+// ParseQuote extracts a Quote from a hub quote payload.
+func ParseQuote(contractID string, data map[string]interface{}) Quote {
+	q := Quote{ContractID: contractID}
+	q.Bid, _ = numberField(data, "bid")
+	q.Ask, _ = numberField(data, "ask")
+	if v, ok := numberField(data, "bidSize"); ok {
+		q.BidSize = int(v)
+	}
+	if v, ok := numberField(data, "askSize"); ok {
+		q.AskSize = int(v)
+	}
+	q.Last, _ = numberField(data, "lastPrice")
+	q.Timestamp = timeField(data, "timestamp")
+	return q
+}
+
+// ParseMarketTrade extracts a MarketTrade from a hub trade payload.
+func ParseMarketTrade(contractID string, data map[string]interface{}) MarketTrade {
+	t := MarketTrade{ContractID: contractID, Side: TradeSideUnknown}
+	t.Price, _ = numberField(data, "price")
+	if v, ok := numberField(data, "size"); ok {
+		t.Size = int(v)
+	}
+	if v, ok := numberField(data, "type"); ok {
+		t.Side = Side(v)
+	}
+	t.Timestamp = timeField(data, "timestamp")
+	return t
+}
+
+// numberField reads a numeric payload field regardless of how it was decoded.
+func numberField(data map[string]interface{}, key string) (float64, bool) {
+	switch v := data[key].(type) {
+	case float64:
+		return v, true
+	case int:
+		return float64(v), true
+	case int64:
+		return float64(v), true
+	case json.Number:
+		f, err := v.Float64()
+		return f, err == nil
+	}
+	return 0, false
+}
+
+// timeField reads an RFC 3339 timestamp payload field, returning the zero time when absent.
+func timeField(data map[string]interface{}, key string) time.Time {
+	s, ok := data[key].(string)
+	if !ok {
+		return time.Time{}
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return time.Time{}
+	}
+	return t
+}
+
+// QuotePriceMode selects the price a quote contributes to a bar.
+type QuotePriceMode int
+
+const (
+	// QuoteMid uses the simple mid, (bid + ask) / 2.
+	QuoteMid QuotePriceMode = iota
+	// QuoteWeightedMid uses the size-weighted mid (microprice),
+	// (bidSize*ask + askSize*bid) / (bidSize + askSize), which leans toward the side
+	// more likely to trade through. It falls back to the simple mid when either size is
+	// missing or zero.
+	QuoteWeightedMid
+)
+
+// Mid returns the quote's price under mode.
+func (q Quote) Mid(mode QuotePriceMode) float64 {
+	if mode == QuoteWeightedMid && q.BidSize > 0 && q.AskSize > 0 {
+		bidSize, askSize := float64(q.BidSize), float64(q.AskSize)
+		return (bidSize*q.Ask + askSize*q.Bid) / (bidSize + askSize)
+	}
+	return (q.Bid + q.Ask) / 2
+}
@@ -1,6 +1,22 @@
 package projectx
 
-import "time"
+import (
+	"fmt"
+	"time"
+)
+
+// BaseResponse is the status block present in every gateway response. Response types
+// embed it, so custom types decoded with Request can too.
+type BaseResponse struct {
+	Success      bool   `json:"success"`
+	ErrorCode    int    `json:"errorCode"`
+	ErrorMessage string `json:"errorMessage"`
+
+	// Field-level details, when the gateway sends them: a validationErrors list, or
+	// the errors map of an ASP.NET validation problem response.
+	ValidationErrors FieldErrors `json:"validationErrors,omitempty"`
+	Errors           FieldErrors `json:"errors,omitempty"`
+}
 
 type LoginRequest struct {
 	UserName string `json:"userName"`
@@ -8,10 +24,8 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token        string `json:"token"`
-	Success      bool   `json:"success"`
-	ErrorCode    int    `json:"errorCode"`
-	ErrorMessage string `json:"errorMessage"`
+	Token string `json:"token"`
+	BaseResponse
 }
 
 type AccountSearchRequest struct {
@@ -19,17 +33,16 @@ type AccountSearchRequest struct {
 }
 
 type Account struct {
-	ID        int    `json:"id"`
-	Name      string `json:"name"`
-	CanTrade  bool   `json:"canTrade"`
-	IsVisible bool   `json:"isVisible"`
+	ID        int     `json:"id"`
+	Name      string  `json:"name"`
+	Balance   float64 `json:"balance"`
+	CanTrade  bool    `json:"canTrade"`
+	IsVisible bool    `json:"isVisible"`
 }
 
 type AccountSearchResponse struct {
-	Accounts     []Account `json:"accounts"`
-	Success      bool      `json:"success"`
-	ErrorCode    int       `json:"errorCode"`
-	ErrorMessage string    `json:"errorMessage"`
+	Accounts []Account `json:"accounts"`
+	BaseResponse
 }
 
 type ContractSearchRequest struct {
@@ -52,37 +65,48 @@ type Contract struct {
 }
 
 type ContractSearchResponse struct {
-	Contracts    []Contract `json:"contracts"`
-	Success      bool       `json:"success"`
-	ErrorCode    int        `json:"errorCode"`
-	ErrorMessage string     `json:"errorMessage"`
+	Contracts []Contract `json:"contracts"`
+	BaseResponse
 }
 
 type ContractSingleResponse struct {
-	Contract     Contract `json:"contract"`
-	Success      bool     `json:"success"`
-	ErrorCode    int      `json:"errorCode"`
-	ErrorMessage string   `json:"errorMessage"`
+	Contract Contract `json:"contract"`
+	BaseResponse
 }
 
+// OrderRequest is the payload for PlaceOrder. Optional fields left nil are
+// omitted from the JSON rather than sent as null, which stricter gateways reject.
 type OrderRequest struct {
 	AccountID     int      `json:"accountId"`
 	ContractID    string   `json:"contractId"`
 	Type          int      `json:"type"`
-	Side          int      `json:"side"`
+	Side          Side     `json:"side"`
 	Size          int      `json:"size"`
-	LimitPrice    *float64 `json:"limitPrice"`
-	StopPrice     *float64 `json:"stopPrice"`
-	TrailPrice    *float64 `json:"trailPrice"`
-	CustomTag     *string  `json:"customTag"`
-	LinkedOrderID *int     `json:"linkedOrderId"`
+	LimitPrice    *float64 `json:"limitPrice,omitempty"`
+	StopPrice     *float64 `json:"stopPrice,omitempty"`
+	TrailPrice    *float64 `json:"trailPrice,omitempty"`
+	CustomTag     *string  `json:"customTag,omitempty"`
+	LinkedOrderID *int     `json:"linkedOrderId,omitempty"`
+
+	// Brackets the gateway attaches when the order fills, see PlaceBracketOrder
+	StopLossBracket   *OrderBracket `json:"stopLossBracket,omitempty"`
+	TakeProfitBracket *OrderBracket `json:"takeProfitBracket,omitempty"`
 }
 
 type OrderResponse struct {
-	OrderID      int    `json:"orderId"`
-	Success      bool   `json:"success"`
-	ErrorCode    int    `json:"errorCode"`
-	ErrorMessage string `json:"errorMessage"`
+	OrderID int `json:"orderId"`
+	BaseResponse
+
+	// Timestamp and Price are not part of the documented response and are
+	// only set when the gateway returns them.
+	Timestamp *time.Time `json:"timestamp,omitempty"`
+	Price     *float64   `json:"price,omitempty"`
+
+	// ReceivedAt is the local time the response was received.
+	ReceivedAt time.Time `json:"-"`
+
+	// DryRun is set on synthetic responses of a client in dry-run mode, see WithDryRun.
+	DryRun bool `json:"-"`
 }
 
 type OrderInfo struct {
@@ -93,10 +117,26 @@ type OrderInfo struct {
 	UpdateTimestamp   *time.Time `json:"updateTimestamp,omitempty"`
 	Status            int        `json:"status"`
 	Type              int        `json:"type"`
-	Side              int        `json:"side"`
+	Side              Side       `json:"side"`
 	Size              int        `json:"size"`
 	LimitPrice        *float64   `json:"limitPrice,omitempty"`
 	StopPrice         *float64   `json:"stopPrice,omitempty"`
+	FillVolume        int        `json:"fillVolume"`
+	FilledPrice       *float64   `json:"filledPrice,omitempty"`
+	CustomTag         string     `json:"customTag,omitempty"`
+}
+
+func (o OrderInfo) IsLimit() bool    { return o.Type == OrderTypeLimit }
+func (o OrderInfo) IsMarket() bool   { return o.Type == OrderTypeMarket }
+func (o OrderInfo) IsStop() bool     { return o.Type == OrderTypeStop }
+func (o OrderInfo) IsTrailing() bool { return o.Type == OrderTypeTrailingStop }
+
+// TypeName returns the readable order type, e.g. "Limit".
+func (o OrderInfo) TypeName() string {
+	if name, ok := OrderTypeName[o.Type]; ok {
+		return name
+	}
+	return fmt.Sprintf("Unknown(%d)", o.Type)
 }
 
 type OrderSearchRequest struct {
@@ -106,10 +146,8 @@ type OrderSearchRequest struct {
 }
 
 type OrderSearchResponse struct {
-	Orders       []OrderInfo `json:"orders"`
-	Success      bool        `json:"success"`
-	ErrorCode    int         `json:"errorCode"`
-	ErrorMessage string      `json:"errorMessage"`
+	Orders []OrderInfo `json:"orders"`
+	BaseResponse
 }
 
 type HistoryRequest struct {
@@ -129,14 +167,16 @@ type HistoryBar struct {
 	High  float64   `json:"h"`
 	Low   float64   `json:"l"`
 	Close float64   `json:"c"`
-	Vol   int       `json:"v"`
+	Vol   int       `json:"v"` // Zero when the gateway omits it; fractional volumes are rounded
+
+	// ContractID is not sent by the gateway; it is filled in from the
+	// request (or the MarketDataManager) so bars keep their provenance.
+	ContractID string `json:"contractId,omitempty"`
 }
 
 type HistoryResponse struct {
-	Bars         []HistoryBar `json:"bars"`
-	Success      bool         `json:"success"`
-	ErrorCode    int          `json:"errorCode"`
-	ErrorMessage string       `json:"errorMessage"`
+	Bars []HistoryBar `json:"bars"`
+	BaseResponse
 }
 
 type Trade struct {
@@ -147,7 +187,7 @@ type Trade struct {
 	Price             float64   `json:"price"`
 	ProfitAndLoss     *float64  `json:"profitAndLoss"`
 	Fees              float64   `json:"fees"`
-	Side              int       `json:"side"`
+	Side              Side      `json:"side"`
 	Size              int       `json:"size"`
 	Voided            bool      `json:"voided"`
 	OrderID           int       `json:"orderId"`
@@ -163,11 +203,22 @@ type OpenPosition struct {
 	AveragePrice      float64 `json:"averagePrice"`
 }
 
+// Side returns SideBuy for long positions and SideSell for short positions.
+func (p OpenPosition) Side() Side {
+	if p.Type == PositionTypeShort {
+		return SideSell
+	}
+	return SideBuy
+}
+
+// SignedSize returns the position size, negative for short positions.
+func (p OpenPosition) SignedSize() int {
+	return p.Side().Sign() * p.Size
+}
+
 type OpenPositionResponse struct {
-	Positions    []OpenPosition `json:"positions"`
-	Success      bool           `json:"success"`
-	ErrorCode    int            `json:"errorCode"`
-	ErrorMessage string         `json:"errorMessage"`
+	Positions []OpenPosition `json:"positions"`
+	BaseResponse
 }
 
 // Time unit constants
@@ -199,11 +250,75 @@ const (
 	OrderTypeJoinAsk      = 7
 )
 
+var OrderTypeName = map[int]string{
+	OrderTypeLimit:        "Limit",
+	OrderTypeMarket:       "Market",
+	OrderTypeStop:         "Stop",
+	OrderTypeTrailingStop: "TrailingStop",
+	OrderTypeJoinBid:      "JoinBid",
+	OrderTypeJoinAsk:      "JoinAsk",
+}
+
+const (
+	OrderStatusNone      = 0
+	OrderStatusOpen      = 1
+	OrderStatusFilled    = 2
+	OrderStatusCancelled = 3
+	OrderStatusExpired   = 4
+	OrderStatusRejected  = 5
+	OrderStatusPending   = 6
+)
+
+var OrderStatusName = map[int]string{
+	OrderStatusNone:      "None",
+	OrderStatusOpen:      "Open",
+	OrderStatusFilled:    "Filled",
+	OrderStatusCancelled: "Cancelled",
+	OrderStatusExpired:   "Expired",
+	OrderStatusRejected:  "Rejected",
+	OrderStatusPending:   "Pending",
+}
+
 const (
 	OrderSideBidBuy  = 0
 	OrderSideAskSell = 1
 )
 
+// Side is the side of an order, trade or position. It (un)marshals as the
+// gateway's integer value, OrderSideBidBuy or OrderSideAskSell.
+type Side int
+
+const (
+	SideBuy  Side = OrderSideBidBuy
+	SideSell Side = OrderSideAskSell
+)
+
+func (s Side) String() string {
+	switch s {
+	case SideBuy:
+		return "Buy"
+	case SideSell:
+		return "Sell"
+	}
+	return fmt.Sprintf("Side(%d)", int(s))
+}
+
+// Opposite returns the other side.
+func (s Side) Opposite() Side {
+	if s == SideBuy {
+		return SideSell
+	}
+	return SideBuy
+}
+
+// Sign returns +1 for buys and -1 for sells.
+func (s Side) Sign() int {
+	if s == SideSell {
+		return -1
+	}
+	return 1
+}
+
 const (
 	PositionTypeLong  = 1
 	PositionTypeShort = 2
@@ -222,3 +337,46 @@ const (
 	PlaceOrderContractNotActive   = 9
 	PlaceOrderAccountRejected     = 10
 )
+
+// ErrorCodeDescriptions maps order placement error codes to human readable
+// descriptions. Add entries for codes that are not listed.
+var ErrorCodeDescriptions = map[int]string{
+	PlaceOrderSuccess:             "Success",
+	PlaceOrderAccountNotFound:     "Account not found",
+	PlaceOrderOrderRejected:       "Order rejected",
+	PlaceOrderInsufficientFunds:   "Insufficient funds",
+	PlaceOrderAccountViolation:    "Account violation",
+	PlaceOrderOutsideTradingHours: "Outside trading hours",
+	PlaceOrderOrderPending:        "Order pending",
+	PlaceOrderUnknownError:        "Unknown error",
+	PlaceOrderContractNotFound:    "Contract not found",
+	PlaceOrderContractNotActive:   "Contract not active",
+	PlaceOrderAccountRejected:     "Account rejected",
+}
+
+// Error codes returned by /api/order/cancel and /api/order/modify.
+const (
+	CancelOrderSuccess         = 0
+	CancelOrderAccountNotFound = 1
+	CancelOrderOrderNotFound   = 2
+	CancelOrderRejected        = 3
+	CancelOrderPending         = 4
+	CancelOrderUnknownError    = 5
+	CancelOrderAccountRejected = 6
+)
+
+// BenignCancelErrorCodes lists cancel error codes that mean the order is no longer
+// working, typically because it filled or was cancelled after the caller's last
+// snapshot. Bulk cancellations treat them as done rather than as failures. The gateway
+// only reports OrderNotFound for such races; add codes here if a deployment differs.
+var BenignCancelErrorCodes = map[int]bool{
+	CancelOrderOrderNotFound: true,
+}
+
+// ErrorCodeDescription returns the description of an order error code.
+func ErrorCodeDescription(code int) string {
+	if desc, ok := ErrorCodeDescriptions[code]; ok {
+		return desc
+	}
+	return fmt.Sprintf("unknown error code %d", code)
+}
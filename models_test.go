@@ -0,0 +1,67 @@
+package projectx
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestOrderRequestJSON(t *testing.T) {
+	price := func(p float64) *float64 { return &p }
+	tag := "entry-1"
+
+	tests := []struct {
+		name  string
+		order OrderRequest
+		want  string
+	}{
+		{
+			name:  "market",
+			order: OrderRequest{AccountID: 7, ContractID: "CON.F.US.EP.H24", Type: OrderTypeMarket, Side: OrderSideBidBuy, Size: 1},
+			want:  `{"accountId":7,"contractId":"CON.F.US.EP.H24","type":2,"side":0,"size":1}`,
+		},
+		{
+			name:  "limit",
+			order: OrderRequest{AccountID: 7, ContractID: "CON.F.US.EP.H24", Type: OrderTypeLimit, Side: OrderSideAskSell, Size: 2, LimitPrice: price(4700.25), CustomTag: &tag},
+			want:  `{"accountId":7,"contractId":"CON.F.US.EP.H24","type":1,"side":1,"size":2,"limitPrice":4700.25,"customTag":"entry-1"}`,
+		},
+		{
+			name:  "stop",
+			order: OrderRequest{AccountID: 7, ContractID: "CON.F.US.EP.H24", Type: OrderTypeStop, Side: OrderSideAskSell, Size: 1, StopPrice: price(4690)},
+			want:  `{"accountId":7,"contractId":"CON.F.US.EP.H24","type":4,"side":1,"size":1,"stopPrice":4690}`,
+		},
+		{
+			name:  "trailing stop",
+			order: OrderRequest{AccountID: 7, ContractID: "CON.F.US.EP.H24", Type: OrderTypeTrailingStop, Side: OrderSideBidBuy, Size: 1, TrailPrice: price(2.5)},
+			want:  `{"accountId":7,"contractId":"CON.F.US.EP.H24","type":5,"side":0,"size":1,"trailPrice":2.5}`,
+		},
+		{
+			name: "market with brackets",
+			order: OrderRequest{AccountID: 7, ContractID: "CON.F.US.EP.H24", Type: OrderTypeMarket, Side: OrderSideBidBuy, Size: 1,
+				StopLossBracket:   &OrderBracket{Ticks: -8, Type: OrderTypeStop},
+				TakeProfitBracket: &OrderBracket{Ticks: 16, Type: OrderTypeLimit}},
+			want: `{"accountId":7,"contractId":"CON.F.US.EP.H24","type":2,"side":0,"size":1,` +
+				`"stopLossBracket":{"ticks":-8,"type":4},"takeProfitBracket":{"ticks":16,"type":1}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := json.Marshal(tt.order)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if string(got) != tt.want {
+				t.Errorf("got  %s\nwant %s", got, tt.want)
+			}
+
+			var fields map[string]json.RawMessage
+			if err := json.Unmarshal(got, &fields); err != nil {
+				t.Fatal(err)
+			}
+			for key, value := range fields {
+				if string(value) == "null" {
+					t.Errorf("%s sent as null instead of omitted", key)
+				}
+			}
+		})
+	}
+}
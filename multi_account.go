@@ -0,0 +1,81 @@
+package projectx
+
+import (
+	"errors"
+	"sync"
+	"time"
+)
+
+// GetAllOpenPositions fetches the open positions of several accounts concurrently.
+// Results for accounts that succeeded are returned even when others fail; the error is
+// a *MultiError listing the failed accounts. Requests go through the client's rate limiter.
+func (c *Client) GetAllOpenPositions(accountIds []int) (map[int][]OpenPosition, error) {
+	return forEachAccount(accountIds, c.GetOpenPositions)
+}
+
+// GetAllOpenOrders fetches the open orders of several accounts concurrently.
+// Partial results and errors are handled as in GetAllOpenPositions.
+func (c *Client) GetAllOpenOrders(accountIds []int) (map[int][]OrderInfo, error) {
+	return forEachAccount(accountIds, c.SearchOpenOrders)
+}
+
+// GetAllTrades fetches the trades of several accounts between start and end concurrently.
+// Partial results and errors are handled as in GetAllOpenPositions.
+func (c *Client) GetAllTrades(accountIds []int, start, end *time.Time) (map[int][]Trade, error) {
+	return forEachAccount(accountIds, func(accountId int) ([]Trade, error) {
+		return c.SearchTrades(accountId, start, end)
+	})
+}
+
+// NetPosition returns the combined position of several accounts in one contract,
+// fetched concurrently: long sizes count positive and short sizes negative, so +3 on
+// one account and -1 on another nets to 2. Accounts that are flat count as zero. On
+// failure the sum covers the accounts that succeeded and the error is a *MultiError
+// listing the others.
+func (c *Client) NetPosition(accountIds []int, contractId string) (int, error) {
+	positions, err := forEachAccount(accountIds, func(accountId int) ([]OpenPosition, error) {
+		position, err := c.GetPositionByContract(accountId, contractId)
+		if errors.Is(err, ErrNotFound) {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		return []OpenPosition{*position}, nil
+	})
+
+	net := 0
+	for _, list := range positions {
+		for _, p := range list {
+			net += p.Side().Sign() * p.Size
+		}
+	}
+	return net, err
+}
+
+// forEachAccount calls fetch for each account concurrently and collects the results by account.
+func forEachAccount[T any](accountIds []int, fetch func(accountId int) ([]T, error)) (map[int][]T, error) {
+	var (
+		wg      sync.WaitGroup
+		mutex   sync.Mutex
+		results = make(map[int][]T, len(accountIds))
+		errs    = &MultiError{Op: "fetch", Kind: "account", Total: len(accountIds)}
+	)
+	for _, accountId := range accountIds {
+		wg.Add(1)
+		go func(accountId int) {
+			defer wg.Done()
+			items, err := fetch(accountId)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			if err != nil {
+				errs.add(accountId, err)
+				return
+			}
+			results[accountId] = items
+		}(accountId)
+	}
+	wg.Wait()
+	return results, errs.err()
+}
@@ -0,0 +1,77 @@
+package projectx
+
+import (
+	"fmt"
+	"strings"
+)
+
+// ItemError is the failure of one item of a bulk operation.
+type ItemError struct {
+	ID  string // Item identifier, e.g. an order, account or contract ID
+	Err error
+}
+
+// MultiError is returned by bulk operations (CancelAllOrders, GetAllOpenPositions,
+// HydrateContracts, ...) when some items fail. Items are listed in the order they
+// failed. errors.Is and errors.As match against any member.
+type MultiError struct {
+	Op     string      // Operation, e.g. "cancel orders"
+	Kind   string      // Item kind used in messages, e.g. "order"
+	Total  int         // Number of items attempted
+	Errors []ItemError // Failed items
+}
+
+func (e *MultiError) Error() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s failed for %d of %d %ss", e.Op, len(e.Errors), e.Total, e.Kind)
+	for _, item := range e.Errors {
+		fmt.Fprintf(&b, "\n  %s %s: %v", e.Kind, item.ID, item.Err)
+	}
+	return b.String()
+}
+
+func (e *MultiError) Unwrap() []error {
+	errs := make([]error, len(e.Errors))
+	for i, item := range e.Errors {
+		errs[i] = item.Err
+	}
+	return errs
+}
+
+// AnyFailed reports whether at least one item failed.
+func (e *MultiError) AnyFailed() bool {
+	return e != nil && len(e.Errors) > 0
+}
+
+// AllFailed reports whether every attempted item failed.
+func (e *MultiError) AllFailed() bool {
+	return e.AnyFailed() && len(e.Errors) >= e.Total
+}
+
+// Failed returns the IDs of the failed items.
+func (e *MultiError) Failed() []string {
+	if e == nil {
+		return nil
+	}
+	ids := make([]string, len(e.Errors))
+	for i, item := range e.Errors {
+		ids[i] = item.ID
+	}
+	return ids
+}
+
+// add records the failure of item id; a nil err is ignored.
+func (e *MultiError) add(id any, err error) {
+	if err != nil {
+		e.Errors = append(e.Errors, ItemError{ID: fmt.Sprint(id), Err: err})
+	}
+}
+
+// err returns e as an error, or nil when nothing failed, so callers never return a
+// non-nil error interface holding an empty MultiError.
+func (e *MultiError) err() error {
+	if !e.AnyFailed() {
+		return nil
+	}
+	return e
+}
@@ -0,0 +1,29 @@
+package projectx
+
+// MultiHandler fans every market data event out to each of its handlers in order, so
+// several consumers (an aggregator, a recorder, a trade tape) can share one SignalR
+// connection.
+type MultiHandler []MarketDataHandler
+
+// NewMultiHandler returns a MultiHandler delivering to handlers in the given order.
+func NewMultiHandler(handlers ...MarketDataHandler) MultiHandler {
+	return MultiHandler(handlers)
+}
+
+func (m MultiHandler) OnQuote(contractID string, data map[string]interface{}) {
+	for _, h := range m {
+		h.OnQuote(contractID, data)
+	}
+}
+
+func (m MultiHandler) OnTrade(contractID string, data map[string]interface{}) {
+	for _, h := range m {
+		h.OnTrade(contractID, data)
+	}
+}
+
+func (m MultiHandler) OnDepth(contractID string, data map[string]interface{}) {
+	for _, h := range m {
+		h.OnDepth(contractID, data)
+	}
+}
@@ -0,0 +1,24 @@
+package projectx
+
+// PointValue returns the currency value of a one point price move for one contract,
+// derived as TickValue / TickSize since the gateway reports no multiplier. It returns
+// 0 when the contract has no tick size.
+func PointValue(contract Contract) float64 {
+	if contract.TickSize <= 0 {
+		return 0
+	}
+	return contract.TickValue / contract.TickSize
+}
+
+// NotionalValue returns the currency notional of size contracts at price:
+//
+//	price * (TickValue / TickSize) * |size|
+//
+// For example 2 ENQ (tick 0.25, tick value $5) at 20000 is 20000 * 20 * 2 = $800,000.
+// The result is always non-negative; use OpenPosition.SignedSize to net long and short exposure.
+func NotionalValue(contract Contract, price float64, size int) float64 {
+	if size < 0 {
+		size = -size
+	}
+	return price * PointValue(contract) * float64(size)
+}
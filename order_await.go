@@ -0,0 +1,141 @@
+package projectx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+)
+
+// orderPollInterval is how often PlaceOrderAwait polls when the user hub is unavailable.
+const orderPollInterval = 250 * time.Millisecond
+
+// orderFetchAttempts bounds how often PlaceOrderAndFetch looks for a placed order that
+// is not yet queryable, orderPollInterval apart.
+const orderFetchAttempts = 8
+
+var customTagCounter atomic.Int64
+
+// WithUserHub lets PlaceOrderAwait confirm orders through the hub's order events.
+func (c *Client) WithUserHub(hub *UserHubClient) *Client {
+	c.userHub = hub
+	return c
+}
+
+// PlaceOrderAwait places the order and blocks until the gateway reports it accepted,
+// rejected or filled, returning the first lifecycle event for it. Confirmation comes from
+// the user hub set with WithUserHub; when none is connected the order is polled instead.
+// Orders without a CustomTag are given a unique one to correlate the hub event.
+//
+// A ctx that is already done fails before the order is sent. Once sent, the placement
+// runs to completion so its outcome is never lost; ctx then bounds the wait for the
+// confirmation only.
+func (c *Client) PlaceOrderAwait(ctx context.Context, order OrderRequest) (*OrderEvent, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	hub := c.userHub
+	if hub == nil || !hub.IsConnected() {
+		return c.placeOrderPoll(ctx, order)
+	}
+
+	if order.CustomTag == nil {
+		tag := fmt.Sprintf("projectx-%d-%d", time.Now().UnixNano(), customTagCounter.Add(1))
+		order.CustomTag = &tag
+	}
+	tag := *order.CustomTag
+
+	// Register before placing, since the hub event can arrive before the REST response
+	var orderID atomic.Int64
+	events, cancel := hub.tracker.wait(func(o OrderInfo) bool {
+		return o.CustomTag == tag || (o.ID != 0 && int64(o.ID) == orderID.Load())
+	})
+	defer cancel()
+
+	resp, err := c.PlaceOrder(order)
+	if err != nil {
+		return nil, err
+	}
+	if resp.DryRun {
+		event := newOrderEvent(OrderPlaced, nil, dryRunOrderInfo(order, resp))
+		return &event, nil
+	}
+	orderID.Store(int64(resp.OrderID))
+
+	// The order may already have been seen under its ID without the tag
+	if o, ok := hub.tracker.Order(resp.OrderID); ok {
+		select {
+		case event := <-events:
+			return &event, nil
+		default:
+		}
+		event := newOrderEvent(OrderPlaced, nil, o)
+		return &event, nil
+	}
+
+	select {
+	case event := <-events:
+		return &event, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// PlaceOrderAndFetch places the order and returns its OrderInfo, so status, prices and
+// timestamps are available without a separate lookup. A freshly placed order can take
+// a moment to appear in searches; it is looked up again for about two seconds before
+// giving up with an error wrapping ErrNotFound.
+func (c *Client) PlaceOrderAndFetch(order OrderRequest) (*OrderInfo, error) {
+	since := time.Now().UTC().Add(-time.Minute)
+	resp, err := c.PlaceOrder(order)
+	if err != nil {
+		return nil, err
+	}
+	if resp.DryRun {
+		info := dryRunOrderInfo(order, resp)
+		return &info, nil
+	}
+
+	for attempt := 1; ; attempt++ {
+		info, err := c.GetOrderByID(order.AccountID, resp.OrderID, since)
+		if err == nil || !errors.Is(err, ErrNotFound) || attempt == orderFetchAttempts {
+			return info, err
+		}
+		time.Sleep(orderPollInterval)
+	}
+}
+
+// placeOrderPoll places the order and searches recent orders until it appears.
+func (c *Client) placeOrderPoll(ctx context.Context, order OrderRequest) (*OrderEvent, error) {
+	start := time.Now().UTC().Add(-time.Minute)
+	resp, err := c.PlaceOrder(order)
+	if err != nil {
+		return nil, err
+	}
+	if resp.DryRun {
+		event := newOrderEvent(OrderPlaced, nil, dryRunOrderInfo(order, resp))
+		return &event, nil
+	}
+
+	ticker := time.NewTicker(orderPollInterval)
+	defer ticker.Stop()
+	for {
+		o, err := c.GetOrderByID(order.AccountID, resp.OrderID, start)
+		if err == nil {
+			eventType, _ := classifyOrderUpdate(nil, *o)
+			event := newOrderEvent(eventType, nil, *o)
+			return &event, nil
+		}
+		if !errors.Is(err, ErrNotFound) {
+			return nil, err
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
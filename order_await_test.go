@@ -0,0 +1,37 @@
+package projectx
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPlaceOrderAwaitDoneContext(t *testing.T) {
+	srv, orders := orderServer(t, func(OrderRequest) bool { return false })
+	c := NewClient(srv.URL)
+	order := OrderRequest{AccountID: 7, ContractID: "CON.F.US.EP.H24", Type: OrderTypeMarket, Side: OrderSideBidBuy, Size: 1}
+
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancel := context.WithDeadline(context.Background(), time.Now().Add(-time.Second))
+	defer cancel()
+
+	for _, tt := range []struct {
+		name string
+		ctx  context.Context
+		want error
+	}{
+		{"cancelled", cancelled, context.Canceled},
+		{"expired", expired, context.DeadlineExceeded},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := c.PlaceOrderAwait(tt.ctx, order); !errors.Is(err, tt.want) {
+				t.Errorf("got error %v, want %v", err, tt.want)
+			}
+			if len(*orders) != 0 {
+				t.Errorf("placed %d orders with a done context", len(*orders))
+			}
+		})
+	}
+}
@@ -0,0 +1,194 @@
+package projectx
+
+import (
+	"sync"
+	"time"
+)
+
+type OrderEventType int
+
+const (
+	OrderPlaced OrderEventType = iota + 1
+	OrderModified
+	OrderPartiallyFilled
+	OrderFilled
+	OrderCancelled
+	OrderRejected
+)
+
+var OrderEventTypeName = map[OrderEventType]string{
+	OrderPlaced:          "Placed",
+	OrderModified:        "Modified",
+	OrderPartiallyFilled: "PartiallyFilled",
+	OrderFilled:          "Filled",
+	OrderCancelled:       "Cancelled",
+	OrderRejected:        "Rejected",
+}
+
+func (t OrderEventType) String() string {
+	return OrderEventTypeName[t]
+}
+
+// OrderEvent is a lifecycle transition of a single order. Before is nil the first
+// time the order is seen. CustomTag is the tag given when the order was placed, so
+// callers can correlate events with their submissions.
+type OrderEvent struct {
+	Type      OrderEventType
+	OrderID   int
+	AccountID int
+	CustomTag string
+	Before    *OrderInfo
+	After     OrderInfo
+	Time      time.Time
+}
+
+// OrderEventCallback receives order lifecycle events.
+type OrderEventCallback func(event OrderEvent)
+
+// OrderTracker turns successive order snapshots, from the user hub or from polling
+// SearchOpenOrders, into lifecycle events. Orders are forgotten once they reach a
+// terminal state (filled, cancelled, expired or rejected).
+type OrderTracker struct {
+	mutex    sync.Mutex
+	orders   map[int]OrderInfo
+	callback OrderEventCallback
+	waiters  map[*orderWaiter]struct{}
+}
+
+// orderWaiter receives the first event for an order matching its predicate.
+type orderWaiter struct {
+	match func(order OrderInfo) bool
+	ch    chan OrderEvent
+}
+
+func NewOrderTracker(callback OrderEventCallback) *OrderTracker {
+	return &OrderTracker{
+		orders:   make(map[int]OrderInfo),
+		callback: callback,
+		waiters:  make(map[*orderWaiter]struct{}),
+	}
+}
+
+// wait registers for the next event of an order selected by match. The returned function
+// unregisters the waiter and must be called once it is no longer needed.
+func (t *OrderTracker) wait(match func(order OrderInfo) bool) (<-chan OrderEvent, func()) {
+	w := &orderWaiter{match: match, ch: make(chan OrderEvent, 1)}
+	t.mutex.Lock()
+	t.waiters[w] = struct{}{}
+	t.mutex.Unlock()
+	return w.ch, func() {
+		t.mutex.Lock()
+		delete(t.waiters, w)
+		t.mutex.Unlock()
+	}
+}
+
+// Update records the latest snapshot of an order and emits the resulting event, if any.
+// The callback is invoked synchronously, outside the tracker's lock.
+func (t *OrderTracker) Update(order OrderInfo) {
+	t.mutex.Lock()
+	var before *OrderInfo
+	if prev, ok := t.orders[order.ID]; ok {
+		before = &prev
+		// Updates do not always repeat the tag
+		if order.CustomTag == "" {
+			order.CustomTag = prev.CustomTag
+		}
+	}
+	eventType, ok := classifyOrderUpdate(before, order)
+	if isTerminalOrderStatus(order.Status) {
+		delete(t.orders, order.ID)
+	} else {
+		t.orders[order.ID] = order
+	}
+	if !ok {
+		t.mutex.Unlock()
+		return
+	}
+
+	event := newOrderEvent(eventType, before, order)
+	for w := range t.waiters {
+		if w.match(order) {
+			w.ch <- event
+			delete(t.waiters, w)
+		}
+	}
+	t.mutex.Unlock()
+
+	if t.callback != nil {
+		t.callback(event)
+	}
+}
+
+func newOrderEvent(eventType OrderEventType, before *OrderInfo, after OrderInfo) OrderEvent {
+	return OrderEvent{
+		Type:      eventType,
+		OrderID:   after.ID,
+		AccountID: after.AccountID,
+		CustomTag: after.CustomTag,
+		Before:    before,
+		After:     after,
+		Time:      orderEventTime(after),
+	}
+}
+
+// Order returns the latest snapshot of a working order.
+func (t *OrderTracker) Order(orderID int) (OrderInfo, bool) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	order, ok := t.orders[orderID]
+	return order, ok
+}
+
+// classifyOrderUpdate determines the event for a transition from before (nil if
+// unseen) to after. It reports false when nothing relevant changed.
+func classifyOrderUpdate(before *OrderInfo, after OrderInfo) (OrderEventType, bool) {
+	if before != nil && before.Status == after.Status && isTerminalOrderStatus(after.Status) {
+		return 0, false
+	}
+	switch after.Status {
+	case OrderStatusRejected:
+		return OrderRejected, true
+	case OrderStatusCancelled, OrderStatusExpired:
+		return OrderCancelled, true
+	case OrderStatusFilled:
+		return OrderFilled, true
+	}
+
+	switch {
+	case before == nil:
+		if after.FillVolume > 0 {
+			return OrderPartiallyFilled, true
+		}
+		return OrderPlaced, true
+	case after.FillVolume > before.FillVolume:
+		return OrderPartiallyFilled, true
+	case after.Size != before.Size ||
+		!equalPrice(after.LimitPrice, before.LimitPrice) ||
+		!equalPrice(after.StopPrice, before.StopPrice):
+		return OrderModified, true
+	}
+	return 0, false
+}
+
+func isTerminalOrderStatus(status int) bool {
+	switch status {
+	case OrderStatusFilled, OrderStatusCancelled, OrderStatusExpired, OrderStatusRejected:
+		return true
+	}
+	return false
+}
+
+func equalPrice(a, b *float64) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+func orderEventTime(order OrderInfo) time.Time {
+	if order.UpdateTimestamp != nil {
+		return *order.UpdateTimestamp
+	}
+	return order.CreationTimestamp
+}
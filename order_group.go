@@ -0,0 +1,150 @@
+package projectx
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// OrderGroup is an entry order together with the orders that depend on it, such as
+// the stop and target of a bracket or the legs of an OCO.
+//
+// The gateway only supports a single LinkedOrderID per order, so groups are tracked
+// client-side: every leg is linked to the entry and the returned OrderGroupHandle
+// records all order IDs so the group can be cancelled as a unit.
+type OrderGroup struct {
+	Entry OrderRequest
+	Legs  []OrderRequest
+}
+
+// OrderGroupHandle identifies the orders placed for an OrderGroup.
+type OrderGroupHandle struct {
+	AccountID int
+	EntryID   int
+	LegIDs    []int
+	Native    bool // Legs are payload brackets managed by the gateway and not in LegIDs
+}
+
+// OrderIDs returns the entry ID followed by the leg IDs.
+func (h *OrderGroupHandle) OrderIDs() []int {
+	return append([]int{h.EntryID}, h.LegIDs...)
+}
+
+// PlaceOrderGroup places the entry order and then each leg, linking legs to the entry
+// unless they already carry a LinkedOrderID. Legs without an account inherit the
+// entry's account. If any leg fails, every order already placed for the group is
+// cancelled and the error is returned together with the partial handle.
+func (c *Client) PlaceOrderGroup(group OrderGroup) (*OrderGroupHandle, error) {
+	entry, err := c.PlaceOrder(group.Entry)
+	if err != nil {
+		return nil, fmt.Errorf("order group entry: %w", err)
+	}
+
+	handle := &OrderGroupHandle{
+		AccountID: group.Entry.AccountID,
+		EntryID:   entry.OrderID,
+	}
+
+	for i, leg := range group.Legs {
+		if leg.AccountID == 0 {
+			leg.AccountID = group.Entry.AccountID
+		}
+		if leg.LinkedOrderID == nil {
+			entryID := entry.OrderID
+			leg.LinkedOrderID = &entryID
+		}
+
+		resp, err := c.PlaceOrder(leg)
+		if err != nil {
+			err = fmt.Errorf("order group leg %d: %w", i, err)
+			if cancelErr := c.CancelOrderGroup(handle); cancelErr != nil {
+				err = errors.Join(err, cancelErr)
+			}
+			return handle, err
+		}
+		handle.LegIDs = append(handle.LegIDs, resp.OrderID)
+	}
+
+	return handle, nil
+}
+
+// CancelOrderGroup cancels every leg of the group and then the entry order.
+// All cancellations are attempted; failures other than benign races are returned as a
+// *MultiError.
+func (c *Client) CancelOrderGroup(handle *OrderGroupHandle) error {
+	return c.cancelOrders(handle.AccountID, append(append([]int(nil), handle.LegIDs...), handle.EntryID))
+}
+
+// OrderGroupWatcher gives order groups OCO semantics for an entry that never fills: the
+// gateway does not cancel linked orders with their entry, so when a tracked entry is
+// cancelled or rejected before any fill, the watcher cancels the group's legs rather than
+// leave stops and targets working without a position.
+//
+// Feed it lifecycle events by calling OnOrderEvent from the UserHubClient callback.
+// A group is forgotten once its entry fills, or once its legs have been cancelled.
+type OrderGroupWatcher struct {
+	mutex   sync.Mutex
+	client  TradingAPI
+	groups  map[int]*OrderGroupHandle // Tracked groups by entry order ID
+	onError func(handle *OrderGroupHandle, err error)
+}
+
+// NewOrderGroupWatcher creates a watcher that cancels orphaned legs through client.
+// onError, which may be nil, receives failures to cancel legs.
+func NewOrderGroupWatcher(client TradingAPI, onError func(handle *OrderGroupHandle, err error)) *OrderGroupWatcher {
+	return &OrderGroupWatcher{
+		client:  client,
+		groups:  make(map[int]*OrderGroupHandle),
+		onError: onError,
+	}
+}
+
+// Track starts watching the entry of a placed group.
+func (w *OrderGroupWatcher) Track(handle *OrderGroupHandle) {
+	w.mutex.Lock()
+	w.groups[handle.EntryID] = handle
+	w.mutex.Unlock()
+}
+
+// Untrack stops watching the group with the given entry order ID.
+func (w *OrderGroupWatcher) Untrack(entryID int) {
+	w.mutex.Lock()
+	delete(w.groups, entryID)
+	w.mutex.Unlock()
+}
+
+// OnOrderEvent handles an order lifecycle event. It has the OrderEventCallback signature.
+func (w *OrderGroupWatcher) OnOrderEvent(event OrderEvent) {
+	w.mutex.Lock()
+	handle, ok := w.groups[event.OrderID]
+	if !ok {
+		w.mutex.Unlock()
+		return
+	}
+
+	orphaned := false
+	switch event.Type {
+	case OrderFilled, OrderPartiallyFilled:
+		// The legs now protect a position
+		delete(w.groups, event.OrderID)
+	case OrderCancelled, OrderRejected:
+		if event.After.FillVolume == 0 {
+			orphaned = true
+		}
+		delete(w.groups, event.OrderID)
+	}
+	w.mutex.Unlock()
+
+	if !orphaned {
+		return
+	}
+	errs := &MultiError{Op: "cancel orphaned legs", Kind: "order", Total: len(handle.LegIDs)}
+	for _, id := range handle.LegIDs {
+		if err := w.client.CancelOrder(handle.AccountID, id); err != nil && !IsBenignCancelError(err) {
+			errs.add(id, err)
+		}
+	}
+	if err := errs.err(); err != nil && w.onError != nil {
+		w.onError(handle, err)
+	}
+}
@@ -0,0 +1,106 @@
+package projectx_test
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/optionsvamp/projectx"
+	"github.com/optionsvamp/projectx/projectxtest"
+)
+
+// placeBracket places an entry with a stop and a target linked to it through fake, as
+// PlaceOrderGroup does, and returns the group's handle.
+func placeBracket(t *testing.T, fake *projectxtest.FakeClient) *projectx.OrderGroupHandle {
+	t.Helper()
+	const account = 7
+	stop, target := 4690.0, 4720.0
+	entry, err := fake.PlaceOrder(projectx.OrderRequest{AccountID: account, ContractID: "CON.F.US.EP.H24", Type: projectx.OrderTypeLimit, Side: projectx.OrderSideBidBuy, Size: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	handle := &projectx.OrderGroupHandle{AccountID: account, EntryID: entry.OrderID}
+	legs := []projectx.OrderRequest{
+		{AccountID: account, ContractID: "CON.F.US.EP.H24", Type: projectx.OrderTypeStop, Side: projectx.OrderSideAskSell, Size: 1, StopPrice: &stop, LinkedOrderID: &entry.OrderID},
+		{AccountID: account, ContractID: "CON.F.US.EP.H24", Type: projectx.OrderTypeLimit, Side: projectx.OrderSideAskSell, Size: 1, LimitPrice: &target, LinkedOrderID: &entry.OrderID},
+	}
+	for _, leg := range legs {
+		resp, err := fake.PlaceOrder(leg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		handle.LegIDs = append(handle.LegIDs, resp.OrderID)
+	}
+	return handle
+}
+
+func TestOrderGroupWatcherCancelEntryBeforeFill(t *testing.T) {
+	for _, eventType := range []projectx.OrderEventType{projectx.OrderCancelled, projectx.OrderRejected} {
+		t.Run(eventType.String(), func(t *testing.T) {
+			fake := projectxtest.NewFakeClient()
+			handle := placeBracket(t, fake)
+			placed := len(fake.PlacedOrders)
+
+			var errs []error
+			w := projectx.NewOrderGroupWatcher(fake, func(_ *projectx.OrderGroupHandle, err error) { errs = append(errs, err) })
+			w.Track(handle)
+			w.OnOrderEvent(projectx.OrderEvent{Type: eventType, OrderID: handle.EntryID, AccountID: handle.AccountID})
+
+			want := []projectxtest.CancelCall{
+				{AccountID: handle.AccountID, OrderID: handle.LegIDs[0]},
+				{AccountID: handle.AccountID, OrderID: handle.LegIDs[1]},
+			}
+			if !reflect.DeepEqual(fake.CancelledOrders, want) {
+				t.Errorf("cancelled %+v, want the legs %+v", fake.CancelledOrders, want)
+			}
+			if len(fake.PlacedOrders) != placed {
+				t.Errorf("watcher placed %d orders", len(fake.PlacedOrders)-placed)
+			}
+			if len(errs) > 0 {
+				t.Errorf("unexpected errors: %v", errs)
+			}
+
+			// The group is forgotten: a repeated event cancels nothing more
+			w.OnOrderEvent(projectx.OrderEvent{Type: eventType, OrderID: handle.EntryID, AccountID: handle.AccountID})
+			if len(fake.CancelledOrders) != len(want) {
+				t.Errorf("repeated event cancelled %d orders, want %d", len(fake.CancelledOrders), len(want))
+			}
+		})
+	}
+}
+
+func TestOrderGroupWatcherKeepsLegsAfterFill(t *testing.T) {
+	fake := projectxtest.NewFakeClient()
+	handle := placeBracket(t, fake)
+
+	w := projectx.NewOrderGroupWatcher(fake, nil)
+	w.Track(handle)
+	w.OnOrderEvent(projectx.OrderEvent{Type: projectx.OrderPartiallyFilled, OrderID: handle.EntryID, After: projectx.OrderInfo{FillVolume: 1}})
+	// The rest of a partially filled entry is cancelled: the legs protect the fill
+	w.OnOrderEvent(projectx.OrderEvent{Type: projectx.OrderCancelled, OrderID: handle.EntryID, After: projectx.OrderInfo{FillVolume: 1}})
+
+	if len(fake.CancelledOrders) != 0 {
+		t.Errorf("cancelled %+v after the entry filled", fake.CancelledOrders)
+	}
+}
+
+func TestOrderGroupWatcherCancelError(t *testing.T) {
+	fake := projectxtest.NewFakeClient()
+	handle := placeBracket(t, fake)
+	boom := errors.New("boom")
+	fake.Errors["CancelOrder"] = boom
+
+	var got error
+	w := projectx.NewOrderGroupWatcher(fake, func(h *projectx.OrderGroupHandle, err error) {
+		if h != handle {
+			t.Errorf("error reported for handle %+v", h)
+		}
+		got = err
+	})
+	w.Track(handle)
+	w.OnOrderEvent(projectx.OrderEvent{Type: projectx.OrderCancelled, OrderID: handle.EntryID})
+
+	if !errors.Is(got, boom) {
+		t.Errorf("got error %v, want %v", got, boom)
+	}
+}
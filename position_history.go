@@ -0,0 +1,137 @@
+package projectx
+
+import (
+	"sort"
+	"time"
+)
+
+type PositionEventKind int
+
+const (
+	PositionOpened  PositionEventKind = iota + 1 // Flat to a position
+	PositionAdded                                // Position increased on the same side
+	PositionReduced                              // Position partly closed
+	PositionClosed                               // Position closed to flat
+)
+
+var PositionEventKindName = map[PositionEventKind]string{
+	PositionOpened:  "Opened",
+	PositionAdded:   "Added",
+	PositionReduced: "Reduced",
+	PositionClosed:  "Closed",
+}
+
+func (k PositionEventKind) String() string {
+	return PositionEventKindName[k]
+}
+
+// PositionEvent is one step in the life of a position, caused by a trade. Prices and
+// realized P&L are in price points; multiply P&L by PointValue for currency.
+type PositionEvent struct {
+	Kind         PositionEventKind
+	AccountID    int
+	ContractID   string
+	TradeID      int
+	Time         time.Time
+	Side         Side    // Side of the trade
+	Size         int     // Contracts of the trade applied in this step
+	Position     int     // Net position after the step, negative when short
+	AveragePrice float64 // Average entry price after the step, zero when flat
+	RealizedPts  float64 // P&L realized by this step, points times contracts
+	TotalPts     float64 // P&L realized so far for the account and contract
+	Fees         float64 // Trade fees attributed to this step
+}
+
+// BuildPositionHistory reconstructs the position ledger from fills, per account and
+// contract, in trade time order (ties broken by trade ID). It uses the average cost
+// method: adds re-average the entry price and reductions realize P&L against the
+// average, which leaves the average unchanged. A trade that flips the position is
+// split into a Closed step followed by an Opened step, with fees split pro rata.
+// Voided trades are skipped.
+func BuildPositionHistory(trades []Trade) []PositionEvent {
+	sorted := make([]Trade, 0, len(trades))
+	for _, t := range trades {
+		if !t.Voided {
+			sorted = append(sorted, t)
+		}
+	}
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if !sorted[i].CreationTimestamp.Equal(sorted[j].CreationTimestamp) {
+			return sorted[i].CreationTimestamp.Before(sorted[j].CreationTimestamp)
+		}
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	type state struct {
+		position int
+		average  float64
+		total    float64
+	}
+	states := make(map[positionKey]*state)
+
+	var events []PositionEvent
+	for _, t := range sorted {
+		key := positionKey{t.AccountID, t.ContractID}
+		st, ok := states[key]
+		if !ok {
+			st = &state{}
+			states[key] = st
+		}
+		if t.Size <= 0 {
+			continue
+		}
+
+		step := func(kind PositionEventKind, size int, realized float64) {
+			st.total += realized
+			events = append(events, PositionEvent{
+				Kind:         kind,
+				AccountID:    t.AccountID,
+				ContractID:   t.ContractID,
+				TradeID:      t.ID,
+				Time:         t.CreationTimestamp,
+				Side:         t.Side,
+				Size:         size,
+				Position:     st.position,
+				AveragePrice: st.average,
+				RealizedPts:  realized,
+				TotalPts:     st.total,
+				Fees:         t.Fees * float64(size) / float64(t.Size),
+			})
+		}
+
+		sign := t.Side.Sign()
+		remaining := t.Size
+
+		// Reduce an opposite position first
+		if st.position != 0 && (st.position > 0) != (sign > 0) {
+			held := st.position
+			if held < 0 {
+				held = -held
+			}
+			closing := min(remaining, held)
+			realized := (t.Price - st.average) * float64(closing) * float64(-sign)
+			st.position += sign * closing
+			remaining -= closing
+			if st.position == 0 {
+				st.average = 0
+				step(PositionClosed, closing, realized)
+			} else {
+				step(PositionReduced, closing, realized)
+			}
+		}
+		if remaining == 0 {
+			continue
+		}
+
+		// Open or add with what is left
+		kind := PositionAdded
+		if st.position == 0 {
+			kind = PositionOpened
+		}
+		held := st.position * sign
+		st.average = (st.average*float64(held) + t.Price*float64(remaining)) / float64(held+remaining)
+		st.position += sign * remaining
+		step(kind, remaining, 0)
+	}
+	return events
+}
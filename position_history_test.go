@@ -0,0 +1,66 @@
+package projectx
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestBuildPositionHistory(t *testing.T) {
+	const es, nq = "CON.F.US.EP.H24", "CON.F.US.ENQ.H24"
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	at := func(minutes int) time.Time { return start.Add(time.Duration(minutes) * time.Minute) }
+	buy, sell := Side(OrderSideBidBuy), Side(OrderSideAskSell)
+
+	// Given out of order; ties at 5 minutes are broken by ID
+	trades := []Trade{
+		{ID: 6, AccountID: 7, ContractID: es, CreationTimestamp: at(5), Side: buy, Size: 3, Price: 96, Fees: 3},
+		{ID: 1, AccountID: 7, ContractID: es, CreationTimestamp: at(0), Side: buy, Size: 2, Price: 100, Fees: 2},
+		{ID: 2, AccountID: 7, ContractID: es, CreationTimestamp: at(1), Side: buy, Size: 1, Price: 103, Fees: 1},
+		{ID: 3, AccountID: 7, ContractID: es, CreationTimestamp: at(2), Side: sell, Size: 1, Price: 105, Fees: 1},
+		{ID: 9, AccountID: 7, ContractID: nq, CreationTimestamp: at(2), Side: sell, Size: 1, Price: 16800},
+		{ID: 8, AccountID: 7, ContractID: es, CreationTimestamp: at(3), Side: sell, Size: 5, Price: 90, Voided: true},
+		{ID: 4, AccountID: 7, ContractID: es, CreationTimestamp: at(3), Side: sell, Size: 2, Price: 99, Fees: 2},
+		{ID: 5, AccountID: 7, ContractID: es, CreationTimestamp: at(5), Side: sell, Size: 1, Price: 98, Fees: 1},
+	}
+
+	want := []PositionEvent{
+		// Scale in: the average re-weights by size
+		{Kind: PositionOpened, ContractID: es, TradeID: 1, Side: buy, Size: 2, Position: 2, AveragePrice: 100, Fees: 2},
+		{Kind: PositionAdded, ContractID: es, TradeID: 2, Side: buy, Size: 1, Position: 3, AveragePrice: 101, Fees: 1},
+		// Partial close realizes against the average, which stays
+		{Kind: PositionReduced, ContractID: es, TradeID: 3, Side: sell, Size: 1, Position: 2, AveragePrice: 101, RealizedPts: 4, TotalPts: 4, Fees: 1},
+		{Kind: PositionOpened, ContractID: nq, TradeID: 9, Side: sell, Size: 1, Position: -1, AveragePrice: 16800},
+		// Exactly flat
+		{Kind: PositionClosed, ContractID: es, TradeID: 4, Side: sell, Size: 2, Position: 0, RealizedPts: -4, TotalPts: 0, Fees: 2},
+		{Kind: PositionOpened, ContractID: es, TradeID: 5, Side: sell, Size: 1, Position: -1, AveragePrice: 98, Fees: 1},
+		// A flip through zero closes the short and opens a long with the rest
+		{Kind: PositionClosed, ContractID: es, TradeID: 6, Side: buy, Size: 1, Position: 0, RealizedPts: 2, TotalPts: 2, Fees: 1},
+		{Kind: PositionOpened, ContractID: es, TradeID: 6, Side: buy, Size: 2, Position: 2, AveragePrice: 96, TotalPts: 2, Fees: 2},
+	}
+
+	got := BuildPositionHistory(trades)
+	if len(got) != len(want) {
+		t.Fatalf("got %d events, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		g := got[i]
+		if g.Kind != w.Kind || g.ContractID != w.ContractID || g.TradeID != w.TradeID || g.Side != w.Side || g.Size != w.Size || g.Position != w.Position {
+			t.Errorf("event %d = %s trade %d %s size %d position %d, want %s trade %d %s size %d position %d", i,
+				g.Kind, g.TradeID, g.ContractID, g.Size, g.Position, w.Kind, w.TradeID, w.ContractID, w.Size, w.Position)
+		}
+		for _, v := range []struct {
+			name      string
+			got, want float64
+		}{
+			{"average", g.AveragePrice, w.AveragePrice},
+			{"realized", g.RealizedPts, w.RealizedPts},
+			{"total", g.TotalPts, w.TotalPts},
+			{"fees", g.Fees, w.Fees},
+		} {
+			if math.Abs(v.got-v.want) > 1e-9 {
+				t.Errorf("event %d (trade %d) %s = %v, want %v", i, g.TradeID, v.name, v.got, v.want)
+			}
+		}
+	}
+}
@@ -0,0 +1,241 @@
+package projectx
+
+import (
+	"sync"
+	"time"
+)
+
+// PositionPnL is the unrealized P&L of an open position at a live mark.
+type PositionPnL struct {
+	Position      OpenPosition
+	Mark          float64 // Latest price for the contract
+	UnrealizedPts float64 // Price move since entry times the signed size
+	UnrealizedPnL float64 // Unrealized P&L in account currency
+	Closed        bool    // Set once when the position is no longer open; the other fields hold its last state
+	Time          time.Time
+}
+
+// PositionPnLCallback receives P&L updates from a PositionPnLStream.
+type PositionPnLCallback func(pnl PositionPnL)
+
+// pnlSubscription is what a PositionPnLStream subscribes to for each position.
+var pnlSubscription = SubscribeOptions{Quotes: true, Trades: true}
+
+// PositionPnLStream emits the unrealized P&L of an account's open positions on every
+// price update of their contracts.
+//
+// It is a MarketDataHandler: install it on the SignalRClient given to the constructor,
+// through a MultiHandler when other handlers share the connection. The stream
+// subscribes that client to the contracts of open positions, and unsubscribes them as
+// positions close. Trade prices and the quote's last price mark the positions.
+//
+// Positions are loaded by Refresh. Feed OnOrderEvent from the UserHubClient callback so
+// fills reload them, since the user hub carries no position updates.
+type PositionPnLStream struct {
+	mutex     sync.Mutex
+	client    TradingAPI
+	feed      *SignalRClient
+	accountID int
+	positions map[string]OpenPosition // Open positions by contract ID
+	contracts map[string]Contract     // Contract specs by contract ID
+	marks     map[string]PositionPnL  // Last update per contract ID
+	emitted   map[string]time.Time    // Time of the last emitted update per contract ID
+	interval  time.Duration           // Minimum time between updates per position
+	callback  PositionPnLCallback
+	onError   func(err error)
+}
+
+// NewPositionPnLStream creates a stream for the positions of accountID, loaded through
+// client and marked from feed, which may be nil when the caller manages subscriptions.
+// onError, which may be nil, receives failures to reload positions or subscribe.
+func NewPositionPnLStream(client TradingAPI, feed *SignalRClient, accountID int, callback PositionPnLCallback, onError func(err error)) *PositionPnLStream {
+	return &PositionPnLStream{
+		client:    client,
+		feed:      feed,
+		accountID: accountID,
+		positions: make(map[string]OpenPosition),
+		contracts: make(map[string]Contract),
+		marks:     make(map[string]PositionPnL),
+		emitted:   make(map[string]time.Time),
+		callback:  callback,
+		onError:   onError,
+	}
+}
+
+// WithInterval throttles updates to at most one per position every interval; ticks in
+// between are dropped and the next tick after the interval carries the latest mark.
+// The default of zero emits on every tick. Refreshes always emit.
+func (s *PositionPnLStream) WithInterval(interval time.Duration) *PositionPnLStream {
+	s.mutex.Lock()
+	s.interval = interval
+	s.mutex.Unlock()
+	return s
+}
+
+// Refresh reloads the account's open positions, subscribes to new contracts and emits
+// an update for every position with a mark, plus a Closed update for positions that
+// are gone.
+func (s *PositionPnLStream) Refresh() error {
+	positions, err := s.client.GetOpenPositions(s.accountID)
+	if err != nil {
+		return err
+	}
+	open := make(map[string]OpenPosition, len(positions))
+	for _, p := range positions {
+		open[p.ContractID] = p
+	}
+
+	// Contract specs are fetched outside the lock, once per contract
+	var added []string
+	s.mutex.Lock()
+	for id := range open {
+		if _, ok := s.contracts[id]; !ok {
+			added = append(added, id)
+		}
+	}
+	s.mutex.Unlock()
+	specs := make(map[string]Contract, len(added))
+	for _, id := range added {
+		contract, err := s.client.GetContractByID(id)
+		if err != nil {
+			return err
+		}
+		specs[id] = *contract
+	}
+
+	now := time.Now()
+	var updates []PositionPnL
+	var opened, closed []string
+	s.mutex.Lock()
+	for id, contract := range specs {
+		s.contracts[id] = contract
+	}
+	for id := range open {
+		if _, ok := s.positions[id]; !ok {
+			opened = append(opened, id)
+		}
+	}
+	for id, p := range s.positions {
+		if _, ok := open[id]; !ok {
+			last := s.marks[id]
+			last.Position, last.Closed, last.Time = p, true, now
+			updates = append(updates, last)
+			delete(s.marks, id)
+			delete(s.emitted, id)
+			closed = append(closed, id)
+		}
+	}
+	s.positions = open
+	for id := range open {
+		if last, ok := s.marks[id]; ok {
+			update := s.mark(id, last.Mark, now)
+			updates = append(updates, update)
+			s.emitted[id] = now
+		}
+	}
+	s.mutex.Unlock()
+
+	s.resubscribe(opened, closed)
+	s.emit(updates)
+	return nil
+}
+
+// Positions returns the latest update of every open position with a mark.
+func (s *PositionPnLStream) Positions() []PositionPnL {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	out := make([]PositionPnL, 0, len(s.marks))
+	for _, pnl := range s.marks {
+		out = append(out, pnl)
+	}
+	return out
+}
+
+// OnOrderEvent reloads positions when an order of the account fills. It has the
+// OrderEventCallback signature.
+func (s *PositionPnLStream) OnOrderEvent(event OrderEvent) {
+	if event.AccountID != s.accountID {
+		return
+	}
+	switch event.Type {
+	case OrderFilled, OrderPartiallyFilled:
+		if err := s.Refresh(); err != nil && s.onError != nil {
+			s.onError(err)
+		}
+	}
+}
+
+func (s *PositionPnLStream) OnQuote(contractID string, data map[string]interface{}) {
+	if last := ParseQuote(contractID, data).Last; last > 0 {
+		s.onPrice(contractID, last)
+	}
+}
+
+func (s *PositionPnLStream) OnTrade(contractID string, data map[string]interface{}) {
+	if price := ParseMarketTrade(contractID, data).Price; price > 0 {
+		s.onPrice(contractID, price)
+	}
+}
+
+func (s *PositionPnLStream) OnDepth(contractID string, data map[string]interface{}) {}
+
+// onPrice marks the position on contractID and emits the update unless throttled.
+func (s *PositionPnLStream) onPrice(contractID string, price float64) {
+	now := time.Now()
+	s.mutex.Lock()
+	if _, ok := s.positions[contractID]; !ok {
+		s.mutex.Unlock()
+		return
+	}
+	update := s.mark(contractID, price, now)
+	if s.interval > 0 && now.Sub(s.emitted[contractID]) < s.interval {
+		s.mutex.Unlock()
+		return
+	}
+	s.emitted[contractID] = now
+	s.mutex.Unlock()
+
+	s.emit([]PositionPnL{update})
+}
+
+// mark records price as the mark of the open position on contractID and returns the
+// update. Callers hold the lock.
+func (s *PositionPnLStream) mark(contractID string, price float64, now time.Time) PositionPnL {
+	p := s.positions[contractID]
+	update := PositionPnL{
+		Position:      p,
+		Mark:          price,
+		UnrealizedPts: (price - p.AveragePrice) * float64(p.SignedSize()),
+		UnrealizedPnL: UnrealizedPnL(p, s.contracts[contractID], price),
+		Time:          now,
+	}
+	s.marks[contractID] = update
+	return update
+}
+
+// resubscribe subscribes the feed to the contracts of opened positions and unsubscribes
+// those of closed ones.
+func (s *PositionPnLStream) resubscribe(opened, closed []string) {
+	if s.feed == nil {
+		return
+	}
+	for _, id := range opened {
+		if err := s.feed.SubscribeStreams(id, pnlSubscription); err != nil && s.onError != nil {
+			s.onError(err)
+		}
+	}
+	for _, id := range closed {
+		if err := s.feed.UnsubscribeStreams(id, pnlSubscription); err != nil && s.onError != nil {
+			s.onError(err)
+		}
+	}
+}
+
+func (s *PositionPnLStream) emit(updates []PositionPnL) {
+	if s.callback == nil {
+		return
+	}
+	for _, u := range updates {
+		s.callback(u)
+	}
+}
@@ -0,0 +1,81 @@
+package projectx
+
+import "math"
+
+// PositionView is an open position enriched with its live mark, unrealized P&L
+// and protective stop, as shown on a dashboard.
+type PositionView struct {
+	OpenPosition
+	MarkPrice      float64    // Latest price for the contract; zero when HasMark is false
+	HasMark        bool       // Whether a mark was available
+	UnrealizedPts  float64    // Price move since entry times the signed size
+	UnrealizedPnL  float64    // Unrealized P&L in account currency; zero without contract specs
+	StopOrder      *OrderInfo // Working stop on the opposite side closest to the mark, if any
+	DistanceToStop *float64   // Price distance from the mark to the stop, negative once the stop is through
+}
+
+// UnrealizedPnL returns the unrealized P&L of a position in account currency at the
+// given mark, using the contract's tick size and tick value.
+func UnrealizedPnL(position OpenPosition, contract Contract, mark float64) float64 {
+	return (mark - position.AveragePrice) * PointValue(contract) * float64(position.SignedSize())
+}
+
+// BuildPositionViews joins positions with working orders and live marks keyed by
+// contract ID. Contracts supply tick size and value for currency P&L and may be nil.
+// It performs no I/O, so callers decide where positions, orders and marks come from.
+func BuildPositionViews(positions []OpenPosition, orders []OrderInfo, marks map[string]float64, contracts []Contract) []PositionView {
+	contractsByID := make(map[string]Contract, len(contracts))
+	for _, c := range contracts {
+		contractsByID[c.ID] = c
+	}
+
+	views := make([]PositionView, 0, len(positions))
+	for _, p := range positions {
+		view := PositionView{OpenPosition: p}
+		view.MarkPrice, view.HasMark = marks[p.ContractID]
+
+		if view.HasMark {
+			view.UnrealizedPts = (view.MarkPrice - p.AveragePrice) * float64(p.SignedSize())
+			if contract, ok := contractsByID[p.ContractID]; ok {
+				view.UnrealizedPnL = UnrealizedPnL(p, contract, view.MarkPrice)
+			}
+		}
+
+		view.StopOrder = protectiveStop(p, orders, view.MarkPrice, view.HasMark)
+		if view.StopOrder != nil && view.HasMark {
+			distance := (view.MarkPrice - *view.StopOrder.StopPrice) * float64(p.Side().Sign())
+			view.DistanceToStop = &distance
+		}
+		views = append(views, view)
+	}
+	return views
+}
+
+// protectiveStop finds the working stop order that closes the position. When several
+// exist, the one nearest the mark is returned, or the first one without a mark.
+func protectiveStop(position OpenPosition, orders []OrderInfo, mark float64, hasMark bool) *OrderInfo {
+	var best *OrderInfo
+	for i := range orders {
+		o := &orders[i]
+		if o.AccountID != position.AccountID || o.ContractID != position.ContractID {
+			continue
+		}
+		if !(o.IsStop() || o.IsTrailing()) || o.StopPrice == nil || o.Side != position.Side().Opposite() {
+			continue
+		}
+		if o.Status != OrderStatusOpen && o.Status != OrderStatusPending {
+			continue
+		}
+		if best == nil {
+			best = o
+			if !hasMark {
+				break
+			}
+			continue
+		}
+		if math.Abs(mark-*o.StopPrice) < math.Abs(mark-*best.StopPrice) {
+			best = o
+		}
+	}
+	return best
+}
@@ -0,0 +1,108 @@
+package projectx
+
+import "strings"
+
+// ProductGroup classifies contracts by asset class. The contract search
+// response carries no group field, so it is derived from the product symbol.
+type ProductGroup int
+
+const (
+	ProductGroupUnknown ProductGroup = iota
+	ProductGroupEquityIndex
+	ProductGroupEnergy
+	ProductGroupMetals
+	ProductGroupInterestRates
+	ProductGroupCurrencies
+	ProductGroupAgriculture
+	ProductGroupCrypto
+)
+
+var ProductGroupName = map[ProductGroup]string{
+	ProductGroupUnknown:       "Unknown",
+	ProductGroupEquityIndex:   "Equity Index",
+	ProductGroupEnergy:        "Energy",
+	ProductGroupMetals:        "Metals",
+	ProductGroupInterestRates: "Interest Rates",
+	ProductGroupCurrencies:    "Currencies",
+	ProductGroupAgriculture:   "Agriculture",
+	ProductGroupCrypto:        "Crypto",
+}
+
+func (g ProductGroup) String() string {
+	if name, ok := ProductGroupName[g]; ok {
+		return name
+	}
+	return ProductGroupName[ProductGroupUnknown]
+}
+
+// ProductGroupBySymbol maps product root symbols, as they appear in contract
+// IDs, to their group. Add entries for products that are not listed.
+var ProductGroupBySymbol = map[string]ProductGroup{
+	"EP":   ProductGroupEquityIndex,
+	"MES":  ProductGroupEquityIndex,
+	"ENQ":  ProductGroupEquityIndex,
+	"MNQ":  ProductGroupEquityIndex,
+	"RTY":  ProductGroupEquityIndex,
+	"M2K":  ProductGroupEquityIndex,
+	"YM":   ProductGroupEquityIndex,
+	"MYM":  ProductGroupEquityIndex,
+	"NKD":  ProductGroupEquityIndex,
+	"CLE":  ProductGroupEnergy,
+	"MCLE": ProductGroupEnergy,
+	"QM":   ProductGroupEnergy,
+	"NGE":  ProductGroupEnergy,
+	"QG":   ProductGroupEnergy,
+	"GCE":  ProductGroupMetals,
+	"MGC":  ProductGroupMetals,
+	"SIE":  ProductGroupMetals,
+	"SIL":  ProductGroupMetals,
+	"HGE":  ProductGroupMetals,
+	"PLE":  ProductGroupMetals,
+	"TUA":  ProductGroupInterestRates,
+	"FVA":  ProductGroupInterestRates,
+	"TYA":  ProductGroupInterestRates,
+	"USA":  ProductGroupInterestRates,
+	"ULA":  ProductGroupInterestRates,
+	"EU6":  ProductGroupCurrencies,
+	"M6E":  ProductGroupCurrencies,
+	"BP6":  ProductGroupCurrencies,
+	"JY6":  ProductGroupCurrencies,
+	"CA6":  ProductGroupCurrencies,
+	"DA6":  ProductGroupCurrencies,
+	"ZC":   ProductGroupAgriculture,
+	"ZS":   ProductGroupAgriculture,
+	"ZW":   ProductGroupAgriculture,
+	"ZL":   ProductGroupAgriculture,
+	"ZM":   ProductGroupAgriculture,
+	"HE":   ProductGroupAgriculture,
+	"LE":   ProductGroupAgriculture,
+	"MBT":  ProductGroupCrypto,
+	"MET":  ProductGroupCrypto,
+}
+
+// ProductGroup returns the contract's product group, derived from the symbol
+// ID when present and from the contract ID otherwise.
+func (c Contract) ProductGroup() ProductGroup {
+	symbol := ""
+	if c.SymbolID != "" {
+		symbol = c.SymbolID[strings.LastIndex(c.SymbolID, ".")+1:]
+	} else if parts, err := ParseContractID(c.ID); err == nil {
+		symbol = parts.Symbol
+	}
+	return ProductGroupBySymbol[symbol]
+}
+
+// FilterContractsByGroup returns the contracts belonging to any of the given groups.
+func FilterContractsByGroup(contracts []Contract, groups ...ProductGroup) []Contract {
+	var filtered []Contract
+	for _, contract := range contracts {
+		group := contract.ProductGroup()
+		for _, g := range groups {
+			if group == g {
+				filtered = append(filtered, contract)
+				break
+			}
+		}
+	}
+	return filtered
+}
@@ -1,7 +1,10 @@
 package projectx
 
 import (
+	"errors"
 	"fmt"
+	"net/url"
+	"strings"
 	"time"
 )
 
@@ -10,77 +13,101 @@ func (c *Client) Login(username, apiKey string) error {
 		UserName: username,
 		APIKey:   apiKey,
 	}
-	var resp LoginResponse
-	if err := c.doRequest("POST", "/api/Auth/loginKey", req, &resp); err != nil {
+	resp, err := typedRequest[LoginResponse](c, "POST", "/api/Auth/loginKey", req, false)
+	if err != nil {
 		return err
 	}
-	if !resp.Success {
-		return fmt.Errorf("login failed: %s", resp.ErrorMessage)
-	}
-	c.Token = resp.Token
+	c.SetToken(resp.Token)
 	return nil
 }
 
 func (c *Client) GetAccounts(onlyActive bool) ([]Account, error) {
 	req := AccountSearchRequest{OnlyActiveAccounts: onlyActive}
-	var resp AccountSearchResponse
-	if err := c.doRequest("POST", "/api/account/search", req, &resp); err != nil {
+	resp, err := Request[AccountSearchResponse](c, "POST", "/api/account/search", req)
+	if err != nil {
 		return nil, err
 	}
-	if !resp.Success {
-		return nil, fmt.Errorf("account search failed: %s", resp.ErrorMessage)
-	}
 	return resp.Accounts, nil
 }
 
+// GetAccount returns the account with the given ID, searching all accounts including
+// inactive ones since the gateway has no single-account endpoint. It returns an error
+// wrapping ErrNotFound when no account has the ID.
+func (c *Client) GetAccount(accountId int) (*Account, error) {
+	accounts, err := c.GetAccounts(false)
+	if err != nil {
+		return nil, err
+	}
+	for i := range accounts {
+		if accounts[i].ID == accountId {
+			return &accounts[i], nil
+		}
+	}
+	return nil, fmt.Errorf("account %d: %w", accountId, ErrNotFound)
+}
+
 func (c *Client) GetContracts(live bool, searchText string) ([]Contract, error) {
 	req := ContractSearchRequest{Live: live, SearchText: searchText}
-	var resp ContractSearchResponse
-	if err := c.doRequest("POST", "/api/contract/search", req, &resp); err != nil {
+	resp, err := Request[ContractSearchResponse](c, "POST", "/api/contract/search", req)
+	if err != nil {
 		return nil, err
 	}
-	if !resp.Success {
-		return nil, fmt.Errorf("contract search failed: %s", resp.ErrorMessage)
-	}
 	return resp.Contracts, nil
 }
 
+// GetContractByID returns the contract with the given ID, or an error wrapping
+// ErrNotFound when the gateway does not know it.
 func (c *Client) GetContractByID(contractID string) (*Contract, error) {
 	req := struct {
 		ContractID string `json:"contractId"`
 	}{ContractID: contractID}
 
-	var resp ContractSingleResponse
-	if err := c.doRequest("POST", "/api/contract/searchById", req, &resp); err != nil {
+	resp, err := Request[ContractSingleResponse](c, "POST", "/api/contract/searchById", req)
+	if err != nil {
 		return nil, err
 	}
-	if !resp.Success {
-		return nil, fmt.Errorf("contract search by ID failed: %s", resp.ErrorMessage)
+	// Unknown IDs are answered with success and an empty contract
+	if resp.Contract.ID == "" {
+		return nil, fmt.Errorf("contract %s: %w", contractID, ErrNotFound)
 	}
 	return &resp.Contract, nil
 }
 
 func (c *Client) GetAvailableContracts(live bool) ([]Contract, error) {
 	req := ContractAvailableRequest{Live: live}
-	var resp ContractSearchResponse
-	if err := c.doRequest("POST", "/api/Contract/available", req, &resp); err != nil {
+	resp, err := Request[ContractSearchResponse](c, "POST", "/api/Contract/available", req)
+	if err != nil {
 		return nil, err
 	}
-	if !resp.Success {
-		return nil, fmt.Errorf("available contracts request failed: %s", resp.ErrorMessage)
-	}
 	return resp.Contracts, nil
 }
 
 func (c *Client) PlaceOrder(order OrderRequest) (*OrderResponse, error) {
-	var resp OrderResponse
-	if err := c.doRequest("POST", "/api/order/place", order, &resp); err != nil {
+	entry := OrderAuditEntry{
+		Time:       time.Now(),
+		Action:     OrderAuditPlace,
+		AccountID:  order.AccountID,
+		ContractID: order.ContractID,
+		Request:    &order,
+	}
+	if c.dryRun {
+		resp := c.dryRunOrder(order)
+		entry.OrderID, entry.Response, entry.DryRun = resp.OrderID, resp, true
+		c.recordOrder(entry)
+		return resp, nil
+	}
+	resp, err := Request[OrderResponse](c, "POST", "/api/order/place", order)
+	entry.Err = err
+	var apiErr *APIError
+	if err != nil && !errors.As(err, &apiErr) {
+		c.recordOrder(entry)
 		return nil, err
 	}
-	if !resp.Success {
-		return &resp, fmt.Errorf("order failed: %s", resp.ErrorMessage)
-	}
-	return &resp, nil
+	resp.ReceivedAt = time.Now()
+	entry.OrderID = resp.OrderID
+	entry.Response = &resp
+	c.recordOrder(entry)
+	return &resp, err
 }
 
 func (c *Client) CancelOrder(accountId, orderId int) error {
@@ -91,18 +118,15 @@ func (c *Client) CancelOrder(accountId, orderId int) error {
 		AccountID: accountId,
 		OrderID:   orderId,
 	}
-	var resp struct {
-		Success      bool   `json:"success"`
-		ErrorCode    int    `json:"errorCode"`
-		ErrorMessage string `json:"errorMessage"`
-	}
-	if err := c.doRequest("POST", "/api/order/cancel", req, &resp); err != nil {
-		return err
-	}
-	if !resp.Success {
-		return fmt.Errorf("order cancel failed: %s", resp.ErrorMessage)
+	sent := time.Now()
+	if c.dryRun {
+		c.logf("dry run: cancel order %d on account %d", orderId, accountId)
+		c.recordOrder(OrderAuditEntry{Time: sent, Action: OrderAuditCancel, AccountID: accountId, OrderID: orderId, DryRun: true})
+		return nil
 	}
-	return nil
+	_, err := Request[struct{}](c, "POST", "/api/order/cancel", req)
+	c.recordOrder(OrderAuditEntry{Time: sent, Action: OrderAuditCancel, AccountID: accountId, OrderID: orderId, Err: err})
+	return err
 }
 
 func (c *Client) ModifyOrder(accountId, orderId int, size *int, limitPrice, stopPrice, trailPrice *float64) error {
@@ -121,18 +145,26 @@ func (c *Client) ModifyOrder(accountId, orderId int, size *int, limitPrice, stop
 		StopPrice:  stopPrice,
 		TrailPrice: trailPrice,
 	}
-	var resp struct {
-		Success      bool   `json:"success"`
-		ErrorCode    int    `json:"errorCode"`
-		ErrorMessage string `json:"errorMessage"`
-	}
-	if err := c.doRequest("POST", "/api/order/modify", req, &resp); err != nil {
-		return err
-	}
-	if !resp.Success {
-		return fmt.Errorf("order modify failed: %s", resp.ErrorMessage)
+	sent := time.Now()
+	var err error
+	if c.dryRun {
+		c.logf("dry run: modify order %d on account %d", orderId, accountId)
+	} else {
+		_, err = Request[struct{}](c, "POST", "/api/order/modify", req)
 	}
-	return nil
+	c.recordOrder(OrderAuditEntry{
+		Time:       sent,
+		Action:     OrderAuditModify,
+		AccountID:  accountId,
+		OrderID:    orderId,
+		Size:       size,
+		LimitPrice: limitPrice,
+		StopPrice:  stopPrice,
+		TrailPrice: trailPrice,
+		Err:        err,
+		DryRun:     c.dryRun,
+	})
+	return err
 }
 
 func (c *Client) GetOpenPositions(accountId int) ([]OpenPosition, error) {
@@ -141,16 +173,29 @@ func (c *Client) GetOpenPositions(accountId int) ([]OpenPosition, error) {
 	}{
 		AccountID: accountId,
 	}
-	var resp OpenPositionResponse
-	if err := c.doRequest("POST", "/api/position/searchOpen", req, &resp); err != nil {
+	resp, err := Request[OpenPositionResponse](c, "POST", "/api/position/searchOpen", req)
+	if err != nil {
 		return nil, err
 	}
-	if !resp.Success {
-		return nil, fmt.Errorf("open position search failed: %s", resp.ErrorMessage)
-	}
 	return resp.Positions, nil
 }
 
+// GetPositionByContract returns the account's open position in the contract. The
+// gateway has no by-contract endpoint, so it filters GetOpenPositions. When the account
+// is flat in the contract it returns nil and an error wrapping ErrNotFound.
+func (c *Client) GetPositionByContract(accountId int, contractId string) (*OpenPosition, error) {
+	positions, err := c.GetOpenPositions(accountId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range positions {
+		if positions[i].ContractID == contractId {
+			return &positions[i], nil
+		}
+	}
+	return nil, fmt.Errorf("position in %s: %w", contractId, ErrNotFound)
+}
+
 func (c *Client) ClosePosition(accountId int, contractId string, size int) error {
 	req := struct {
 		AccountID  int    `json:"accountId"`
@@ -161,18 +206,17 @@ func (c *Client) ClosePosition(accountId int, contractId string, size int) error
 		ContractID: contractId,
 		Size:       size,
 	}
-	var resp struct {
-		Success      bool   `json:"success"`
-		ErrorCode    int    `json:"errorCode"`
-		ErrorMessage string `json:"errorMessage"`
-	}
-	if err := c.doRequest("POST", "/api/position/closeContract", req, &resp); err != nil {
-		return err
-	}
-	if !resp.Success {
-		return fmt.Errorf("position close failed: %s", resp.ErrorMessage)
-	}
-	return nil
+	entry := OrderAuditEntry{Time: time.Now(), Action: OrderAuditClose, AccountID: accountId, ContractID: contractId, Size: &size}
+	if c.dryRun {
+		c.logf("dry run: close %d of %s on account %d", size, contractId, accountId)
+		entry.DryRun = true
+		c.recordOrder(entry)
+		return nil
+	}
+	_, err := Request[struct{}](c, "POST", "/api/position/closeContract", req)
+	entry.Err = err
+	c.recordOrder(entry)
+	return err
 }
 
 func (c *Client) PartialClosePosition(accountId int, contractId string, size int) error {
@@ -185,82 +229,257 @@ func (c *Client) PartialClosePosition(accountId int, contractId string, size int
 		ContractID: contractId,
 		Size:       size,
 	}
-	var resp struct {
-		Success      bool   `json:"success"`
-		ErrorCode    int    `json:"errorCode"`
-		ErrorMessage string `json:"errorMessage"`
+	entry := OrderAuditEntry{Time: time.Now(), Action: OrderAuditPartialClose, AccountID: accountId, ContractID: contractId, Size: &size}
+	if c.dryRun {
+		c.logf("dry run: partially close %d of %s on account %d", size, contractId, accountId)
+		entry.DryRun = true
+		c.recordOrder(entry)
+		return nil
+	}
+	_, err := Request[struct{}](c, "POST", "/api/position/partialCloseContract", req)
+	entry.Err = err
+	c.recordOrder(entry)
+	return err
+}
+
+func (c *Client) GetHistoricalBars(req HistoryRequest) ([]HistoryBar, error) {
+	if err := req.Validate(); err != nil {
+		return nil, fmt.Errorf("historical data request failed: %w", err)
 	}
-	if err := c.doRequest("POST", "/api/position/partialCloseContract", req, &resp); err != nil {
-		return err
+	resp, err := Request[HistoryResponse](c, "POST", "/api/history/retrieveBars", req)
+	if err != nil {
+		return nil, err
 	}
-	if !resp.Success {
-		return fmt.Errorf("partial position close failed: %s", resp.ErrorMessage)
+	for i := range resp.Bars {
+		resp.Bars[i].ContractID = req.ContractID
 	}
-	return nil
+	return resp.Bars, nil
 }
 
-func (c *Client) GetHistoricalBars(req HistoryRequest) ([]HistoryBar, error) {
-	var resp HistoryResponse
-	if err := c.doRequest("POST", "/api/history/retrieveBars", req, &resp); err != nil {
+// GetQuote returns a one-shot quote for the contract without a hub subscription. The
+// gateway has no REST quote endpoint, so the quote is taken from the latest partial
+// one-minute bar: only Last and Timestamp are set, Bid/Ask and sizes are zero.
+// It returns an error wrapping ErrNotFound when the contract has no recent bars.
+func (c *Client) GetQuote(contractId string) (*Quote, error) {
+	now := time.Now().UTC()
+	bars, err := c.GetHistoricalBars(HistoryRequest{
+		ContractID:        contractId,
+		StartTime:         now.Add(-96 * time.Hour), // Spans weekends and holidays
+		EndTime:           now,
+		Unit:              TimeUnitMinute,
+		UnitNumber:        1,
+		Limit:             1,
+		IncludePartialBar: true,
+	})
+	if err != nil {
 		return nil, err
 	}
-	if !resp.Success {
-		if len(resp.ErrorMessage) == 0 {
-			return nil, fmt.Errorf("historical data request failed: code = %d", resp.ErrorCode)
+	if len(bars) == 0 {
+		return nil, fmt.Errorf("quote for %s: %w", contractId, ErrNotFound)
+	}
+
+	latest := bars[0]
+	for _, bar := range bars[1:] {
+		if bar.Time.After(latest.Time) {
+			latest = bar
 		}
-		return nil, fmt.Errorf("historical data request failed: %s", resp.ErrorMessage)
 	}
-	return resp.Bars, nil
+	return &Quote{
+		ContractID: contractId,
+		Last:       latest.Close,
+		Timestamp:  latest.Time,
+	}, nil
 }
 
+// SearchOrders returns orders created within the request window. The timestamps are
+// converted to UTC before sending.
 func (c *Client) SearchOrders(req OrderSearchRequest) ([]OrderInfo, error) {
-	var resp OrderSearchResponse
-	if err := c.doRequest("POST", "/api/order/search", req, &resp); err != nil {
-		return nil, err
+	req.StartTimestamp = req.StartTimestamp.UTC()
+	if req.EndTimestamp != nil {
+		end := req.EndTimestamp.UTC()
+		req.EndTimestamp = &end
 	}
-	if !resp.Success {
-		return nil, fmt.Errorf("order search failed: %s", resp.ErrorMessage)
+	resp, err := Request[OrderSearchResponse](c, "POST", "/api/order/search", req)
+	if err != nil {
+		return nil, err
 	}
 	return resp.Orders, nil
 }
 
+// SearchOrdersToday returns orders created since midnight UTC of the current day.
+// The window is always computed in UTC regardless of the local time zone, so
+// "today" rolls over at 00:00 UTC rather than at the exchange session open.
+func (c *Client) SearchOrdersToday(accountId int) ([]OrderInfo, error) {
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return c.SearchOrders(OrderSearchRequest{
+		AccountID:      accountId,
+		StartTimestamp: start,
+	})
+}
+
+// SearchOrdersLast returns orders created within the trailing duration d, up to now.
+// Both ends of the window are sent in UTC.
+func (c *Client) SearchOrdersLast(accountId int, d time.Duration) ([]OrderInfo, error) {
+	end := time.Now().UTC()
+	start := end.Add(-d)
+	return c.SearchOrders(OrderSearchRequest{
+		AccountID:      accountId,
+		StartTimestamp: start,
+		EndTimestamp:   &end,
+	})
+}
+
 func (c *Client) SearchOpenOrders(accountId int) ([]OrderInfo, error) {
 	req := struct {
 		AccountID int `json:"accountId"`
 	}{AccountID: accountId}
-	var resp OrderSearchResponse
-	if err := c.doRequest("POST", "/api/order/searchOpen", req, &resp); err != nil {
+	resp, err := Request[OrderSearchResponse](c, "POST", "/api/order/searchOpen", req)
+	if err != nil {
 		return nil, err
 	}
-	if !resp.Success {
-		return nil, fmt.Errorf("open order search failed: %s", resp.ErrorMessage)
-	}
 	return resp.Orders, nil
 }
 
+// GetOrderByID returns the order with the given ID among the account's orders created
+// since the given time. The gateway has no single-order endpoint, so pass a time at or
+// before the order's creation. It returns an error wrapping ErrNotFound when no such
+// order exists.
+func (c *Client) GetOrderByID(accountId, orderId int, since time.Time) (*OrderInfo, error) {
+	orders, err := c.SearchOrders(OrderSearchRequest{AccountID: accountId, StartTimestamp: since})
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		if orders[i].ID == orderId {
+			return &orders[i], nil
+		}
+	}
+	return nil, fmt.Errorf("order %d: %w", orderId, ErrNotFound)
+}
+
+// FindOpenOrderByTag returns the open order placed with the given custom tag.
+// It returns an error wrapping ErrNotFound when no open order carries the tag.
+func (c *Client) FindOpenOrderByTag(accountId int, customTag string) (*OrderInfo, error) {
+	orders, err := c.SearchOpenOrders(accountId)
+	if err != nil {
+		return nil, err
+	}
+	for i := range orders {
+		if orders[i].CustomTag == customTag {
+			return &orders[i], nil
+		}
+	}
+	return nil, fmt.Errorf("open order with tag %q: %w", customTag, ErrNotFound)
+}
+
+// ModifyOrderByTag modifies the open order placed with the given custom tag, so callers
+// tracking orders by their own tag need not store the server-assigned ID.
+func (c *Client) ModifyOrderByTag(accountId int, customTag string, size *int, limitPrice, stopPrice, trailPrice *float64) error {
+	order, err := c.FindOpenOrderByTag(accountId, customTag)
+	if err != nil {
+		return err
+	}
+	return c.ModifyOrder(accountId, order.ID, size, limitPrice, stopPrice, trailPrice)
+}
+
+// CancelOrdersByTag cancels every open order whose CustomTag starts with tagPrefix, e.g.
+// all orders of one strategy tagged "strategyX-...". Matching is a case-sensitive prefix
+// match, so pass a full tag to match exactly one; an empty prefix matches every order.
+// All cancellations are attempted; failures are returned as a *MultiError. Orders
+// that filled or were cancelled after the search are not failures, see
+// IsBenignCancelError.
+func (c *Client) CancelOrdersByTag(accountId int, tagPrefix string) error {
+	orders, err := c.SearchOpenOrders(accountId)
+	if err != nil {
+		return err
+	}
+	var ids []int
+	for _, o := range orders {
+		if strings.HasPrefix(o.CustomTag, tagPrefix) {
+			ids = append(ids, o.ID)
+		}
+	}
+	return c.cancelOrders(accountId, ids)
+}
+
+// CancelAllOrders cancels every open order of the account, e.g. as a kill switch. It
+// behaves like CancelOrdersByTag with an empty prefix.
+func (c *Client) CancelAllOrders(accountId int) error {
+	return c.CancelOrdersByTag(accountId, "")
+}
+
+// cancelOrders cancels each order, ignoring benign races, and returns the failures as
+// a *MultiError.
+func (c *Client) cancelOrders(accountId int, ids []int) error {
+	errs := &MultiError{Op: "cancel orders", Kind: "order", Total: len(ids)}
+	for _, id := range ids {
+		if err := c.CancelOrder(accountId, id); err != nil && !IsBenignCancelError(err) {
+			errs.add(id, err)
+		}
+	}
+	return errs.err()
+}
+
+// SearchTrades returns trades executed between start and end. start is required; a nil
+// end means up to now. Both times are converted to UTC before sending, so times in any
+// location select the same instants; the gateway would otherwise be sent local offsets.
 func (c *Client) SearchTrades(accountId int, start, end *time.Time) ([]Trade, error) {
+	if start == nil {
+		return nil, fmt.Errorf("trade search failed: start time is required")
+	}
 	type request struct {
 		AccountID      int        `json:"accountId"`
 		StartTimestamp time.Time  `json:"startTimestamp"`
 		EndTimestamp   *time.Time `json:"endTimestamp,omitempty"`
 	}
 	type response struct {
-		Trades       []Trade `json:"trades"`
-		Success      bool    `json:"success"`
-		ErrorCode    int     `json:"errorCode"`
-		ErrorMessage string  `json:"errorMessage"`
+		Trades []Trade `json:"trades"`
+		BaseResponse
 	}
 	req := request{
 		AccountID:      accountId,
-		StartTimestamp: *start,
-		EndTimestamp:   end,
+		StartTimestamp: start.UTC(),
 	}
-	var resp response
-	if err := c.doRequest("POST", "/api/trade/search", req, &resp); err != nil {
-		return nil, err
+	if end != nil {
+		utcEnd := end.UTC()
+		req.EndTimestamp = &utcEnd
 	}
-	if !resp.Success {
-		return nil, fmt.Errorf("trade search failed: %s", resp.ErrorMessage)
+	resp, err := Request[response](c, "POST", "/api/trade/search", req)
+	if err != nil {
+		return nil, err
 	}
 	return resp.Trades, nil
 }
+
+// SearchTradesToday returns trades executed since midnight UTC of the current day,
+// matching the window of SearchOrdersToday.
+func (c *Client) SearchTradesToday(accountId int) ([]Trade, error) {
+	now := time.Now().UTC()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	return c.SearchTrades(accountId, &start, nil)
+}
+
+// SearchTradesLast returns trades executed within the trailing duration d, up to now.
+func (c *Client) SearchTradesLast(accountId int, d time.Duration) ([]Trade, error) {
+	end := time.Now().UTC()
+	start := end.Add(-d)
+	return c.SearchTrades(accountId, &start, &end)
+}
+
+// Ping verifies connectivity and authentication with a minimal, side-effect free account
+// search, for use in health and readiness checks. Network failures wrap ErrUnreachable;
+// authentication failures wrap ErrUnauthorized (or the refresh error when auto retry is
+// configured); other gateway rejections are returned as *APIError.
+func (c *Client) Ping() error {
+	req := AccountSearchRequest{OnlyActiveAccounts: true}
+	_, err := Request[AccountSearchResponse](c, "POST", "/api/account/search", req)
+	if err == nil {
+		return nil
+	}
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Errorf("ping: %w: %w", ErrUnreachable, err)
+	}
+	return fmt.Errorf("ping: %w", err)
+}
@@ -0,0 +1,74 @@
+package projectx
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// recordingServer answers every request with a successful empty search and records the
+// raw request bodies.
+func recordingServer(t *testing.T) (*httptest.Server, *[]map[string]json.RawMessage) {
+	t.Helper()
+	var bodies []map[string]json.RawMessage
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, _ := io.ReadAll(r.Body)
+		var body map[string]json.RawMessage
+		if err := json.Unmarshal(data, &body); err != nil {
+			t.Errorf("decode request: %v", err)
+		}
+		bodies = append(bodies, body)
+		w.Write([]byte(`{"success":true,"errorCode":0,"orders":[],"trades":[]}`))
+	}))
+	t.Cleanup(srv.Close)
+	return srv, &bodies
+}
+
+func TestSearchTimestampsUTC(t *testing.T) {
+	newYork := time.FixedZone("EST", -5*60*60)
+	start := time.Date(2024, 1, 2, 9, 30, 0, 0, newYork)
+	end := time.Date(2024, 1, 2, 16, 0, 0, 0, newYork)
+	const wantStart, wantEnd = `"2024-01-02T14:30:00Z"`, `"2024-01-02T21:00:00Z"`
+
+	srv, bodies := recordingServer(t)
+	c := NewClient(srv.URL)
+
+	if _, err := c.SearchOrders(OrderSearchRequest{AccountID: 7, StartTimestamp: start, EndTimestamp: &end}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := c.SearchTrades(7, &start, &end); err != nil {
+		t.Fatal(err)
+	}
+	for i, body := range *bodies {
+		if got := string(body["startTimestamp"]); got != wantStart {
+			t.Errorf("request %d start %s, want %s", i, got, wantStart)
+		}
+		if got := string(body["endTimestamp"]); got != wantEnd {
+			t.Errorf("request %d end %s, want %s", i, got, wantEnd)
+		}
+	}
+	if !end.Equal(time.Date(2024, 1, 2, 16, 0, 0, 0, newYork)) || end.Location() != newYork {
+		t.Errorf("caller's end time modified to %s", end)
+	}
+
+	// A missing end is omitted rather than sent as null
+	*bodies = nil
+	if _, err := c.SearchTrades(7, &start, nil); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := (*bodies)[0]["endTimestamp"]; ok {
+		t.Errorf("nil end sent as %s", (*bodies)[0]["endTimestamp"])
+	}
+
+	// A missing start fails before anything is sent
+	*bodies = nil
+	if _, err := c.SearchTrades(7, nil, &end); err == nil {
+		t.Error("nil start accepted")
+	}
+	if len(*bodies) != 0 {
+		t.Errorf("sent %d requests for a nil start", len(*bodies))
+	}
+}
@@ -0,0 +1,290 @@
+// Package projectxtest provides test doubles for code built on the projectx package.
+package projectxtest
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/optionsvamp/projectx"
+)
+
+// CancelCall records a CancelOrder call.
+type CancelCall struct {
+	AccountID int
+	OrderID   int
+}
+
+// ModifyCall records a ModifyOrder call.
+type ModifyCall struct {
+	AccountID  int
+	OrderID    int
+	Size       *int
+	LimitPrice *float64
+	StopPrice  *float64
+	TrailPrice *float64
+}
+
+// CloseCall records a ClosePosition or PartialClosePosition call.
+type CloseCall struct {
+	AccountID  int
+	ContractID string
+	Size       int
+	Partial    bool
+}
+
+// FakeClient is an in-memory projectx.TradingAPI. Read methods return the canned
+// data in its exported fields, filtered by account where applicable; mutating
+// methods record their arguments. Errors keyed by method name (e.g. "PlaceOrder")
+// are returned instead of performing the call.
+//
+// Lock the embedded mutex when reading or writing fields while the fake is in use
+// from other goroutines.
+type FakeClient struct {
+	sync.Mutex
+
+	Accounts  []projectx.Account
+	Contracts []projectx.Contract
+	Positions []projectx.OpenPosition
+	Orders    []projectx.OrderInfo
+	Trades    []projectx.Trade
+	Bars      []projectx.HistoryBar
+	Errors    map[string]error
+
+	PlacedOrders    []projectx.OrderRequest
+	CancelledOrders []CancelCall
+	ModifiedOrders  []ModifyCall
+	ClosedPositions []CloseCall
+
+	Token       string
+	NextOrderID int
+}
+
+var _ projectx.TradingAPI = (*FakeClient)(nil)
+
+func NewFakeClient() *FakeClient {
+	return &FakeClient{
+		Errors:      make(map[string]error),
+		NextOrderID: 1,
+	}
+}
+
+func (f *FakeClient) Login(username, apiKey string) error {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["Login"]; err != nil {
+		return err
+	}
+	f.Token = "fake-token"
+	return nil
+}
+
+func (f *FakeClient) GetAccounts(onlyActive bool) ([]projectx.Account, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["GetAccounts"]; err != nil {
+		return nil, err
+	}
+	var accounts []projectx.Account
+	for _, a := range f.Accounts {
+		if !onlyActive || a.CanTrade {
+			accounts = append(accounts, a)
+		}
+	}
+	return accounts, nil
+}
+
+func (f *FakeClient) GetContracts(live bool, searchText string) ([]projectx.Contract, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["GetContracts"]; err != nil {
+		return nil, err
+	}
+	search := strings.ToLower(searchText)
+	var contracts []projectx.Contract
+	for _, c := range f.Contracts {
+		if strings.Contains(strings.ToLower(c.Name), search) || strings.Contains(strings.ToLower(c.ID), search) {
+			contracts = append(contracts, c)
+		}
+	}
+	return contracts, nil
+}
+
+func (f *FakeClient) GetContractByID(contractID string) (*projectx.Contract, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["GetContractByID"]; err != nil {
+		return nil, err
+	}
+	for _, c := range f.Contracts {
+		if c.ID == contractID {
+			return &c, nil
+		}
+	}
+	return nil, fmt.Errorf("contract %s: %w", contractID, projectx.ErrNotFound)
+}
+
+func (f *FakeClient) GetAvailableContracts(live bool) ([]projectx.Contract, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["GetAvailableContracts"]; err != nil {
+		return nil, err
+	}
+	return append([]projectx.Contract(nil), f.Contracts...), nil
+}
+
+func (f *FakeClient) PlaceOrder(order projectx.OrderRequest) (*projectx.OrderResponse, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["PlaceOrder"]; err != nil {
+		return nil, err
+	}
+	f.PlacedOrders = append(f.PlacedOrders, order)
+	resp := &projectx.OrderResponse{
+		OrderID:      f.NextOrderID,
+		BaseResponse: projectx.BaseResponse{Success: true},
+		ReceivedAt:   time.Now(),
+	}
+	f.NextOrderID++
+	return resp, nil
+}
+
+func (f *FakeClient) CancelOrder(accountId, orderId int) error {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["CancelOrder"]; err != nil {
+		return err
+	}
+	f.CancelledOrders = append(f.CancelledOrders, CancelCall{AccountID: accountId, OrderID: orderId})
+	return nil
+}
+
+func (f *FakeClient) ModifyOrder(accountId, orderId int, size *int, limitPrice, stopPrice, trailPrice *float64) error {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["ModifyOrder"]; err != nil {
+		return err
+	}
+	f.ModifiedOrders = append(f.ModifiedOrders, ModifyCall{
+		AccountID:  accountId,
+		OrderID:    orderId,
+		Size:       size,
+		LimitPrice: limitPrice,
+		StopPrice:  stopPrice,
+		TrailPrice: trailPrice,
+	})
+	return nil
+}
+
+func (f *FakeClient) GetOpenPositions(accountId int) ([]projectx.OpenPosition, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["GetOpenPositions"]; err != nil {
+		return nil, err
+	}
+	var positions []projectx.OpenPosition
+	for _, p := range f.Positions {
+		if p.AccountID == accountId {
+			positions = append(positions, p)
+		}
+	}
+	return positions, nil
+}
+
+func (f *FakeClient) ClosePosition(accountId int, contractId string, size int) error {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["ClosePosition"]; err != nil {
+		return err
+	}
+	f.ClosedPositions = append(f.ClosedPositions, CloseCall{AccountID: accountId, ContractID: contractId, Size: size})
+	return nil
+}
+
+func (f *FakeClient) PartialClosePosition(accountId int, contractId string, size int) error {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["PartialClosePosition"]; err != nil {
+		return err
+	}
+	f.ClosedPositions = append(f.ClosedPositions, CloseCall{AccountID: accountId, ContractID: contractId, Size: size, Partial: true})
+	return nil
+}
+
+func (f *FakeClient) GetHistoricalBars(req projectx.HistoryRequest) ([]projectx.HistoryBar, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["GetHistoricalBars"]; err != nil {
+		return nil, err
+	}
+	var bars []projectx.HistoryBar
+	for _, b := range f.Bars {
+		if b.Time.Before(req.StartTime) || b.Time.After(req.EndTime) {
+			continue
+		}
+		b.ContractID = req.ContractID
+		bars = append(bars, b)
+	}
+	return bars, nil
+}
+
+func (f *FakeClient) SearchOrders(req projectx.OrderSearchRequest) ([]projectx.OrderInfo, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["SearchOrders"]; err != nil {
+		return nil, err
+	}
+	var orders []projectx.OrderInfo
+	for _, o := range f.Orders {
+		if o.AccountID != req.AccountID || o.CreationTimestamp.Before(req.StartTimestamp) {
+			continue
+		}
+		if req.EndTimestamp != nil && o.CreationTimestamp.After(*req.EndTimestamp) {
+			continue
+		}
+		orders = append(orders, o)
+	}
+	return orders, nil
+}
+
+func (f *FakeClient) SearchOpenOrders(accountId int) ([]projectx.OrderInfo, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["SearchOpenOrders"]; err != nil {
+		return nil, err
+	}
+	// Like the gateway, only working orders are returned
+	var orders []projectx.OrderInfo
+	for _, o := range f.Orders {
+		if o.AccountID != accountId {
+			continue
+		}
+		if o.Status == projectx.OrderStatusOpen || o.Status == projectx.OrderStatusPending {
+			orders = append(orders, o)
+		}
+	}
+	return orders, nil
+}
+
+func (f *FakeClient) SearchTrades(accountId int, start, end *time.Time) ([]projectx.Trade, error) {
+	f.Lock()
+	defer f.Unlock()
+	if err := f.Errors["SearchTrades"]; err != nil {
+		return nil, err
+	}
+	var trades []projectx.Trade
+	for _, t := range f.Trades {
+		if t.AccountID != accountId {
+			continue
+		}
+		if start != nil && t.CreationTimestamp.Before(*start) {
+			continue
+		}
+		if end != nil && t.CreationTimestamp.After(*end) {
+			continue
+		}
+		trades = append(trades, t)
+	}
+	return trades, nil
+}
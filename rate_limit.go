@@ -0,0 +1,74 @@
+package projectx
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket that admits n requests per interval, refilling continuously.
+type rateLimiter struct {
+	mutex    sync.Mutex
+	tokens   float64
+	capacity float64
+	rate     float64 // Tokens added per second
+	last     time.Time
+}
+
+func newRateLimiter(n int, per time.Duration) *rateLimiter {
+	return &rateLimiter{
+		tokens:   float64(n),
+		capacity: float64(n),
+		rate:     float64(n) / per.Seconds(),
+		last:     time.Now(),
+	}
+}
+
+// wait blocks until a request may be made or ctx is done.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	for {
+		l.mutex.Lock()
+		now := time.Now()
+		l.tokens += now.Sub(l.last).Seconds() * l.rate
+		if l.tokens > l.capacity {
+			l.tokens = l.capacity
+		}
+		l.last = now
+
+		if l.tokens >= 1 {
+			l.tokens--
+			l.mutex.Unlock()
+			return nil
+		}
+		delay := time.Duration((1 - l.tokens) / l.rate * float64(time.Second))
+		l.mutex.Unlock()
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// concurrencyLimiter caps the number of requests in flight at once.
+type concurrencyLimiter chan struct{}
+
+func newConcurrencyLimiter(n int) concurrencyLimiter {
+	return make(concurrencyLimiter, n)
+}
+
+// acquire blocks until a slot is free or ctx is done. Call release when the request
+// has completed.
+func (l concurrencyLimiter) acquire(ctx context.Context) error {
+	select {
+	case l <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l concurrencyLimiter) release() {
+	<-l
+}
@@ -0,0 +1,81 @@
+package projectx
+
+import "sort"
+
+type PositionDiffKind int
+
+const (
+	PositionMissing      PositionDiffKind = iota + 1 // Expected locally but not held at the broker
+	PositionUnexpected                               // Held at the broker but not expected locally
+	PositionSideMismatch                             // Held on the opposite side
+	PositionSizeMismatch                             // Same side, different size
+)
+
+var PositionDiffKindName = map[PositionDiffKind]string{
+	PositionMissing:      "Missing",
+	PositionUnexpected:   "Unexpected",
+	PositionSideMismatch: "SideMismatch",
+	PositionSizeMismatch: "SizeMismatch",
+}
+
+func (k PositionDiffKind) String() string {
+	return PositionDiffKindName[k]
+}
+
+// PositionDiff is a discrepancy between an expected (local) and an actual (remote) position.
+// Local or Remote is nil when the position exists on one side only.
+type PositionDiff struct {
+	Kind       PositionDiffKind
+	AccountID  int
+	ContractID string
+	Local      *OpenPosition
+	Remote     *OpenPosition
+}
+
+type positionKey struct {
+	accountID  int
+	contractID string
+}
+
+// ReconcilePositions compares the positions a strategy expects to hold against those
+// reported by the broker, keyed by account and contract ID. Each input is expected to
+// hold at most one position per key. Diffs are ordered by account and contract ID.
+func ReconcilePositions(local []OpenPosition, remote []OpenPosition) []PositionDiff {
+	remoteByKey := make(map[positionKey]*OpenPosition, len(remote))
+	for i := range remote {
+		p := &remote[i]
+		remoteByKey[positionKey{p.AccountID, p.ContractID}] = p
+	}
+
+	var diffs []PositionDiff
+	seen := make(map[positionKey]bool, len(local))
+	for i := range local {
+		l := &local[i]
+		key := positionKey{l.AccountID, l.ContractID}
+		seen[key] = true
+
+		r, ok := remoteByKey[key]
+		switch {
+		case !ok:
+			diffs = append(diffs, PositionDiff{Kind: PositionMissing, AccountID: l.AccountID, ContractID: l.ContractID, Local: l})
+		case l.Type != r.Type:
+			diffs = append(diffs, PositionDiff{Kind: PositionSideMismatch, AccountID: l.AccountID, ContractID: l.ContractID, Local: l, Remote: r})
+		case l.Size != r.Size:
+			diffs = append(diffs, PositionDiff{Kind: PositionSizeMismatch, AccountID: l.AccountID, ContractID: l.ContractID, Local: l, Remote: r})
+		}
+	}
+
+	for key, r := range remoteByKey {
+		if !seen[key] {
+			diffs = append(diffs, PositionDiff{Kind: PositionUnexpected, AccountID: r.AccountID, ContractID: r.ContractID, Remote: r})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		if diffs[i].AccountID != diffs[j].AccountID {
+			return diffs[i].AccountID < diffs[j].AccountID
+		}
+		return diffs[i].ContractID < diffs[j].ContractID
+	})
+	return diffs
+}
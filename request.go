@@ -0,0 +1,189 @@
+package projectx
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+)
+
+// APIError is returned when the gateway responds with success=false.
+type APIError struct {
+	Endpoint     string
+	ErrorCode    int
+	ErrorMessage string
+	FieldErrors  []FieldError // Field-level details, when the response carries them
+}
+
+func (e *APIError) Error() string {
+	op, ok := endpointOperations[e.Endpoint]
+	if !ok {
+		op = e.Endpoint
+	}
+	var msg string
+	switch {
+	case len(e.ErrorMessage) > 0:
+		msg = fmt.Sprintf("%s failed: %s", op, e.ErrorMessage)
+	case e.Endpoint == "/api/order/place" && (e.ErrorCode != 0 || len(e.FieldErrors) == 0):
+		msg = fmt.Sprintf("%s failed: %s", op, ErrorCodeDescription(e.ErrorCode))
+	case len(e.FieldErrors) > 0:
+		msg = fmt.Sprintf("%s failed: invalid request", op)
+	default:
+		msg = fmt.Sprintf("%s failed: code = %d", op, e.ErrorCode)
+	}
+	for i, fe := range e.FieldErrors {
+		sep := "; "
+		if i == 0 {
+			sep = " ("
+		}
+		msg += sep + fe.String()
+	}
+	if len(e.FieldErrors) > 0 {
+		msg += ")"
+	}
+	return msg
+}
+
+// FieldError is a validation failure of one request field.
+type FieldError struct {
+	Field   string // Request field, empty when the gateway does not name one
+	Message string
+}
+
+func (e FieldError) String() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + ": " + e.Message
+}
+
+// FieldErrors decodes the shapes gateways use for field-level error details: a list
+// of objects with a field or propertyName and a message or errorMessage, a list of
+// strings, or a map from field to one or more messages. Other shapes decode to nil
+// rather than failing the response.
+type FieldErrors []FieldError
+
+func (f *FieldErrors) UnmarshalJSON(data []byte) error {
+	*f = nil
+
+	var list []json.RawMessage
+	if err := json.Unmarshal(data, &list); err == nil {
+		for _, item := range list {
+			var text string
+			if json.Unmarshal(item, &text) == nil {
+				*f = append(*f, FieldError{Message: text})
+				continue
+			}
+			var obj struct {
+				Field        string `json:"field"`
+				PropertyName string `json:"propertyName"`
+				Message      string `json:"message"`
+				ErrorMessage string `json:"errorMessage"`
+			}
+			if json.Unmarshal(item, &obj) != nil {
+				continue
+			}
+			fe := FieldError{Field: obj.Field, Message: obj.Message}
+			if fe.Field == "" {
+				fe.Field = obj.PropertyName
+			}
+			if fe.Message == "" {
+				fe.Message = obj.ErrorMessage
+			}
+			*f = append(*f, fe)
+		}
+		return nil
+	}
+
+	var byField map[string]json.RawMessage
+	if err := json.Unmarshal(data, &byField); err != nil {
+		return nil
+	}
+	fields := make([]string, 0, len(byField))
+	for field := range byField {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	for _, field := range fields {
+		var messages []string
+		if json.Unmarshal(byField[field], &messages) != nil {
+			var single string
+			if json.Unmarshal(byField[field], &single) != nil {
+				continue
+			}
+			messages = []string{single}
+		}
+		for _, m := range messages {
+			*f = append(*f, FieldError{Field: field, Message: m})
+		}
+	}
+	return nil
+}
+
+// AsError returns the *APIError for endpoint when the response reports success=false,
+// and nil otherwise.
+func (r BaseResponse) AsError(endpoint string) error {
+	if r.Success {
+		return nil
+	}
+	return &APIError{
+		Endpoint:     endpoint,
+		ErrorCode:    r.ErrorCode,
+		ErrorMessage: r.ErrorMessage,
+		FieldErrors:  append(append([]FieldError(nil), r.ValidationErrors...), r.Errors...),
+	}
+}
+
+// IsBenignCancelError reports whether err is an order cancel failure listed in
+// BenignCancelErrorCodes, i.e. the order was already filled or cancelled.
+func IsBenignCancelError(err error) bool {
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || apiErr.Endpoint != "/api/order/cancel" {
+		return false
+	}
+	return BenignCancelErrorCodes[apiErr.ErrorCode]
+}
+
+// endpointOperations names endpoints in error messages. Endpoints not listed are named by path.
+var endpointOperations = map[string]string{
+	"/api/Auth/loginKey":                 "login",
+	"/api/account/search":                "account search",
+	"/api/contract/search":               "contract search",
+	"/api/contract/searchById":           "contract search by ID",
+	"/api/Contract/available":            "available contracts request",
+	"/api/order/place":                   "order",
+	"/api/order/cancel":                  "order cancel",
+	"/api/order/modify":                  "order modify",
+	"/api/order/search":                  "order search",
+	"/api/order/searchOpen":              "open order search",
+	"/api/position/searchOpen":           "open position search",
+	"/api/position/closeContract":        "position close",
+	"/api/position/partialCloseContract": "partial position close",
+	"/api/history/retrieveBars":          "historical data request",
+	"/api/trade/search":                  "trade search",
+}
+
+// Request sends body to endpoint and decodes the response into T, handling marshaling,
+// authentication and the 401 refresh the same way as the Client methods. When the
+// response reports success=false it returns an *APIError together with the decoded T.
+// Use it to call endpoints the Client does not wrap yet.
+func Request[T any](c *Client, method, endpoint string, body any) (T, error) {
+	return typedRequest[T](c, method, endpoint, body, true)
+}
+
+func typedRequest[T any](c *Client, method, endpoint string, body any, auth bool) (T, error) {
+	var out T
+	var raw json.RawMessage
+	if err := c.send(method, endpoint, body, &raw, auth); err != nil {
+		return out, err
+	}
+
+	var env BaseResponse
+	if err := json.Unmarshal(raw, &env); err != nil {
+		return out, err
+	}
+	if err := json.Unmarshal(raw, &out); err != nil {
+		return out, err
+	}
+	return out, env.AsError(endpoint)
+}
@@ -0,0 +1,119 @@
+package projectx
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// The gateway has no server-time endpoint, so server time is read from the HTTP Date
+// header, which every response carries, including errors. The header has one second
+// resolution; ClockOffset narrows that by combining several samples.
+
+// ClockSync is a measured offset between the local clock and the gateway clock.
+type ClockSync struct {
+	Offset      time.Duration // Server time minus local time
+	Uncertainty time.Duration // The true offset lies within Offset ± Uncertainty
+}
+
+// ServerClock returns a clock reading local time corrected by the offset, suitable for
+// MarketDataManager.WithClock.
+func (s ClockSync) ServerClock() func() time.Time {
+	return func() time.Time {
+		return time.Now().Add(s.Offset)
+	}
+}
+
+// ServerTime returns the gateway's current time from a single Date header, accurate to
+// about one second plus half the round trip.
+func (c *Client) ServerTime() (time.Time, error) {
+	date, _, recv, err := c.sampleServerDate(context.Background())
+	if err != nil {
+		return time.Time{}, err
+	}
+	// The header truncates to the second; assume the middle of that second
+	return date.Add(500 * time.Millisecond).Add(time.Since(recv)), nil
+}
+
+// ClockOffset measures the local-to-server clock offset from the Date headers of the
+// given number of requests (at least one). Each sample bounds the offset between
+// date-received and date+1s-sent; the bounds are intersected, so several samples spread
+// over a second or two narrow the result towards the round-trip time.
+func (c *Client) ClockOffset(ctx context.Context, samples int) (ClockSync, error) {
+	if samples < 1 {
+		samples = 1
+	}
+
+	var lo, hi time.Duration
+	for i := 0; i < samples; i++ {
+		if i > 0 {
+			// Spread samples across second boundaries of the Date header
+			select {
+			case <-ctx.Done():
+				return ClockSync{}, ctx.Err()
+			case <-time.After(time.Second / time.Duration(samples) * 3 / 2):
+			}
+		}
+		date, sent, recv, err := c.sampleServerDate(ctx)
+		if err != nil {
+			return ClockSync{}, err
+		}
+		sampleLo := date.Sub(recv)
+		sampleHi := date.Add(time.Second).Sub(sent)
+		if i == 0 || sampleLo > lo {
+			lo = sampleLo
+		}
+		if i == 0 || sampleHi < hi {
+			hi = sampleHi
+		}
+	}
+	if lo > hi {
+		// Samples disagree, e.g. the local clock stepped while sampling
+		return ClockSync{}, errors.New("clock offset failed: inconsistent samples")
+	}
+	return ClockSync{Offset: (lo + hi) / 2, Uncertainty: (hi - lo) / 2}, nil
+}
+
+// sampleServerDate requests the base URL and returns the Date header with the local
+// send and receive times.
+func (c *Client) sampleServerDate(ctx context.Context) (date, sent, recv time.Time, err error) {
+	if c.inflight != nil {
+		if err = c.inflight.acquire(ctx); err != nil {
+			return
+		}
+		defer c.inflight.release()
+	}
+	if c.limiter != nil {
+		if err = c.limiter.wait(ctx); err != nil {
+			return
+		}
+	}
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, c.BaseURL, nil)
+	if err != nil {
+		return
+	}
+	req.Header.Set("User-Agent", c.UserAgent)
+
+	sent = time.Now()
+	resp, err := c.httpClient.Do(req)
+	recv = time.Now()
+	if err != nil {
+		err = fmt.Errorf("server time request failed: %w", err)
+		return
+	}
+	resp.Body.Close()
+
+	date, err = http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		err = fmt.Errorf("server time request failed: no valid Date header: %v", err)
+	}
+	return
+}
@@ -0,0 +1,186 @@
+package projectx
+
+import (
+	"sync"
+	"time"
+)
+
+// ContractStats are the statistics of one contract for the current session.
+type ContractStats struct {
+	ContractID    string
+	SessionStart  time.Time // Start of the session the statistics cover
+	Open          float64   // First price of the session
+	High          float64
+	Low           float64
+	Last          float64
+	PrevClose     float64 // Last price of the previous session, zero when unknown
+	NetChange     float64 // Last minus PrevClose, or minus Open when PrevClose is unknown
+	PercentChange float64 // NetChange as a percentage of its reference price
+	Updated       time.Time
+}
+
+// SessionStats is a MarketDataHandler tracking session open, high, low, last and change
+// for every contract it receives. Trade prices and the quote's last price update the
+// statistics; depth is ignored. It is safe for concurrent use; combine it with other
+// handlers through MultiHandler.
+//
+// Sessions start every day at the same wall-clock time in a location, e.g. 17:00
+// America/Chicago for CME Globex. The first price at or after a session start resets
+// the contract's statistics and carries the previous last price over as PrevClose.
+type SessionStats struct {
+	mutex    sync.RWMutex
+	location *time.Location
+	start    time.Duration // Session start as an offset from local midnight
+	clock    func() time.Time
+	stats    map[string]*ContractStats
+}
+
+// NewSessionStats creates a tracker whose sessions start at the given offset from
+// midnight in location, e.g. 17*time.Hour. A nil location means UTC.
+func NewSessionStats(location *time.Location, start time.Duration) *SessionStats {
+	if location == nil {
+		location = time.UTC
+	}
+	return &SessionStats{
+		location: location,
+		start:    start,
+		stats:    make(map[string]*ContractStats),
+	}
+}
+
+// WithClock sets the time source used to assign prices to sessions, e.g.
+// ClockSync.ServerClock. Nil restores time.Now.
+func (s *SessionStats) WithClock(clock func() time.Time) *SessionStats {
+	s.mutex.Lock()
+	s.clock = clock
+	s.mutex.Unlock()
+	return s
+}
+
+// SetPreviousClose seeds the reference for the change of contractID, e.g. from the
+// settlement in historical bars, before the first live price of the session.
+func (s *SessionStats) SetPreviousClose(contractID string, price float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	st, ok := s.stats[contractID]
+	if !ok {
+		st = &ContractStats{ContractID: contractID}
+		s.stats[contractID] = st
+	}
+	st.PrevClose = price
+	st.computeChange()
+}
+
+// Stats returns the current session statistics of contractID, or false before its
+// first price.
+func (s *SessionStats) Stats(contractID string) (ContractStats, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	st, ok := s.stats[contractID]
+	if !ok || st.Updated.IsZero() {
+		return ContractStats{}, false
+	}
+	return *st, true
+}
+
+// SessionStart returns the start of the session containing t.
+func (s *SessionStats) SessionStart(t time.Time) time.Time {
+	// time.Date normalizes the offset in wall-clock time, so starts stay put across DST
+	local := t.In(s.location)
+	start := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, int(s.start), s.location)
+	if local.Before(start) {
+		start = time.Date(local.Year(), local.Month(), local.Day()-1, 0, 0, 0, int(s.start), s.location)
+	}
+	return start
+}
+
+// Current is Stats for the session in progress: it returns false until the first price
+// of the session containing the clock's time, even when the previous session's
+// statistics are still held.
+func (s *SessionStats) Current(contractID string) (ContractStats, bool) {
+	s.mutex.RLock()
+	defer s.mutex.RUnlock()
+	st, ok := s.stats[contractID]
+	if !ok || st.Updated.IsZero() || !st.SessionStart.Equal(s.SessionStart(s.now())) {
+		return ContractStats{}, false
+	}
+	return *st, true
+}
+
+// now returns the current time from the configured clock. Callers hold the lock.
+func (s *SessionStats) now() time.Time {
+	if s.clock != nil {
+		return s.clock()
+	}
+	return time.Now()
+}
+
+func (s *SessionStats) OnQuote(contractID string, data map[string]interface{}) {
+	if last := ParseQuote(contractID, data).Last; last > 0 {
+		s.update(contractID, last)
+	}
+}
+
+func (s *SessionStats) OnTrade(contractID string, data map[string]interface{}) {
+	if price := ParseMarketTrade(contractID, data).Price; price > 0 {
+		s.update(contractID, price)
+	}
+}
+
+func (s *SessionStats) OnDepth(contractID string, data map[string]interface{}) {}
+
+// update applies a price, starting a new session first when the price falls after the
+// current session.
+func (s *SessionStats) update(contractID string, price float64) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	session := s.SessionStart(now)
+
+	st, ok := s.stats[contractID]
+	if !ok {
+		st = &ContractStats{ContractID: contractID}
+		s.stats[contractID] = st
+	}
+	if !st.SessionStart.Equal(session) {
+		prevClose := st.PrevClose
+		if !st.Updated.IsZero() {
+			prevClose = st.Last
+		}
+		*st = ContractStats{
+			ContractID:   contractID,
+			SessionStart: session,
+			Open:         price,
+			High:         price,
+			Low:          price,
+			PrevClose:    prevClose,
+		}
+	}
+
+	if price > st.High {
+		st.High = price
+	}
+	if price < st.Low {
+		st.Low = price
+	}
+	st.Last = price
+	st.Updated = now
+	st.computeChange()
+}
+
+// computeChange derives the change fields from Last.
+func (st *ContractStats) computeChange() {
+	if st.Updated.IsZero() {
+		return
+	}
+	ref := st.PrevClose
+	if ref == 0 {
+		ref = st.Open
+	}
+	st.NetChange = st.Last - ref
+	st.PercentChange = 0
+	if ref != 0 {
+		st.PercentChange = st.NetChange / ref * 100
+	}
+}
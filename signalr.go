@@ -2,11 +2,13 @@ package projectx
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"net/http"
 	"net/url"
 	"sync"
+	"time"
 
 	"github.com/philippseith/signalr"
 )
@@ -22,67 +24,292 @@ type MarketDataHandler interface {
 // SignalRClient manages the WebSocket connection to the market data hub using SignalR.
 // It handles connection lifecycle, subscription management, and message routing.
 type SignalRClient struct {
-	client         signalr.Client     // The underlying SignalR client
-	mutex          sync.RWMutex       // Protects access to shared state
-	subscriptions  map[string]bool    // Tracks active contract subscriptions
-	marketHandler  MarketDataHandler  // Handles market data events
-	isConnected    bool               // Current connection state
-	reconnectCount int                // Number of reconnection attempts
-	ctx            context.Context    // Context for cancellation
-	cancel         context.CancelFunc // Function to cancel the context
+	client         signalr.Client              // The underlying SignalR client
+	mutex          sync.RWMutex                // Protects access to shared state
+	subscriptions  map[string]streamRefs       // Tracks subscribers of each stream per contract
+	lastUpdate     map[string]time.Time        // Time of the last message received per contract
+	stats          map[string]StreamStats      // Messages received per stream and contract
+	marketHandler  MarketDataHandler           // Handles market data events
+	isConnected    bool                        // Current connection state
+	connectionID   string                      // Connection ID assigned by the hub, empty when disconnected
+	reconnectCount int                         // Number of reconnection attempts
+	ctx            context.Context             // Context for cancellation
+	cancel         context.CancelFunc          // Function to cancel the context
+	config         signalRConfig               // Connection settings used for every (re)connect
+	token          string                      // JWT used for the next (re)connect
+	pending        signalr.Connection          // Connection dialed by the constructor, handed out on first connect
+	connCancel     context.CancelFunc          // Cancels the current connection, forcing a reconnect
+	onGiveUp       func(lastErr error)         // Called once reconnection is abandoned
+	gaveUp         bool                        // Set once the reconnect policy has given up
+	reconnecting   bool                        // Set while the reconnect loop is running
+	rawTap         func(string, []interface{}) // Optional tap for every incoming message
+}
+
+// DefaultMarketHubURL is the market data hub used unless WithHubURL is given.
+const DefaultMarketHubURL = "https://rtc.thefuturesdesk.projectx.com/hubs/market"
+
+// SignalROption configures the hub connection created by NewSignalRClient.
+type SignalROption func(*signalRConfig)
+
+// signalRConfig holds the connection settings collected from SignalROptions.
+type signalRConfig struct {
+	hubURL    string          // Hub endpoint to connect to
+	query     url.Values      // Extra query parameters for the negotiate/connect URL
+	headers   http.Header     // Extra HTTP headers for the negotiate/connect requests
+	reconnect ReconnectPolicy // How lost connections are re-established
+	negotiate time.Duration   // Upper bound for negotiating a connection, zero for none
+	keepAlive time.Duration   // Ping interval when no other message is sent
+	timeout   time.Duration   // Silence from the hub after which the connection is dropped
+	lookup    ContractLookup  // Validates contract IDs before subscribing, nil for none
+}
+
+// ContractLookup resolves a contract ID, returning an error wrapping ErrNotFound when
+// it does not exist. Client.GetContractByIDCached satisfies it.
+type ContractLookup func(contractID string) (*Contract, error)
+
+// DefaultNegotiateTimeout bounds connection negotiation unless WithNegotiateTimeout is given.
+const DefaultNegotiateTimeout = 30 * time.Second
+
+// Default keep-alive settings, matching the SignalR protocol defaults.
+const (
+	DefaultKeepAliveInterval = 15 * time.Second
+	DefaultServerTimeout     = 30 * time.Second
+)
+
+// newSignalRConfig returns the default connection settings for the given hub with opts applied.
+func newSignalRConfig(hubURL string, opts []SignalROption) signalRConfig {
+	cfg := signalRConfig{
+		hubURL:    hubURL,
+		query:     url.Values{},
+		headers:   http.Header{},
+		reconnect: DefaultReconnectPolicy,
+		negotiate: DefaultNegotiateTimeout,
+		keepAlive: DefaultKeepAliveInterval,
+		timeout:   DefaultServerTimeout,
+	}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	return cfg
+}
+
+// WithHubURL overrides the hub endpoint, for brokers that host the hub elsewhere.
+func WithHubURL(hubURL string) SignalROption {
+	return func(cfg *signalRConfig) {
+		cfg.hubURL = hubURL
+	}
+}
+
+// WithHubQueryParam adds a query parameter to the hub URL, e.g. a clientProtocol version
+// required by stricter negotiation.
+func WithHubQueryParam(key, value string) SignalROption {
+	return func(cfg *signalRConfig) {
+		cfg.query.Add(key, value)
+	}
+}
+
+// WithHubHeader adds an HTTP header to the hub negotiation and connection requests.
+func WithHubHeader(key, value string) SignalROption {
+	return func(cfg *signalRConfig) {
+		cfg.headers.Add(key, value)
+	}
+}
+
+// WithNegotiateTimeout bounds how long negotiating and opening a hub connection may take,
+// so an unreachable hub fails fast instead of blocking Start. Zero disables the timeout.
+func WithNegotiateTimeout(timeout time.Duration) SignalROption {
+	return func(cfg *signalRConfig) {
+		cfg.negotiate = timeout
+	}
+}
+
+// WithKeepAlive sets how often the client pings the hub when idle and how long the hub
+// may stay silent before the connection is considered lost and reconnected. The defaults
+// are 15s and 30s. On high-latency or lossy links raise serverTimeout (e.g. to 60s) to
+// avoid spurious reconnects; keep it at least twice the keep-alive interval, as the hub
+// pings on a similar schedule. Values from 5s to a few minutes are sensible.
+func WithKeepAlive(interval, serverTimeout time.Duration) SignalROption {
+	return func(cfg *signalRConfig) {
+		cfg.keepAlive = interval
+		cfg.timeout = serverTimeout
+	}
+}
+
+// WithContractValidation makes subscriptions look each contract ID up first and fail
+// with ErrUnknownContract for IDs the gateway does not know. Without it the hub accepts
+// any ID and a typo yields a subscription that never delivers data. Pass
+// client.GetContractByIDCached so each contract costs one REST call at most.
+func WithContractValidation(lookup ContractLookup) SignalROption {
+	return func(cfg *signalRConfig) {
+		cfg.lookup = lookup
+	}
+}
+
+// validate checks settings that cannot be checked when each option is applied.
+func (cfg signalRConfig) validate() error {
+	if cfg.keepAlive <= 0 || cfg.timeout <= cfg.keepAlive {
+		return fmt.Errorf("invalid keep-alive settings: interval %s must be positive and below server timeout %s", cfg.keepAlive, cfg.timeout)
+	}
+	return nil
+}
+
+// newHubClient creates the underlying SignalR client with the configured keep-alive
+// settings, dialing through connector and delivering hub messages to receiver.
+func newHubClient(ctx context.Context, cfg signalRConfig, connector func() (signalr.Connection, error), receiver interface{}) (signalr.Client, error) {
+	c, err := signalr.NewClient(ctx,
+		signalr.WithConnector(connector),
+		signalr.WithReceiver(receiver),
+		signalr.KeepAliveInterval(cfg.keepAlive),
+		signalr.TimeoutInterval(cfg.timeout))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create SignalR client: %v", err)
+	}
+	return c, nil
 }
 
 // NewSignalRClient creates a new SignalR client with the given JWT token and market data handler.
 // It establishes a WebSocket connection to the market data hub and sets up message handling.
-func NewSignalRClient(jwtToken string, marketHandler MarketDataHandler) (*SignalRClient, error) {
+//
+// The WebSocket transport, the access_token query parameter and the Authorization header are
+// always set from jwtToken; they take precedence over values supplied through options.
+func NewSignalRClient(jwtToken string, marketHandler MarketDataHandler, opts ...SignalROption) (*SignalRClient, error) {
+	// Collect connection settings
+	cfg := newSignalRConfig(DefaultMarketHubURL, opts)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
 	// Create a cancellable context for the client
 	ctx, cancel := context.WithCancel(context.Background())
 
 	// Initialize the client structure
 	client := &SignalRClient{
-		subscriptions: make(map[string]bool),
+		subscriptions: make(map[string]streamRefs),
+		lastUpdate:    make(map[string]time.Time),
+		stats:         make(map[string]StreamStats),
 		marketHandler: marketHandler,
 		ctx:           ctx,
 		cancel:        cancel,
+		config:        cfg,
+		token:         jwtToken,
 	}
 
+	// Dial the first connection up front so configuration and network errors surface here
+	conn, err := client.dial()
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	client.pending = conn
+
+	// Create SignalR client and register this instance as the message receiver.
+	// The connector is used for the initial connection and every reconnect, so a
+	// token replaced with UpdateToken is picked up on the next reconnect.
+	c, err := newHubClient(ctx, cfg, client.connect, client)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	client.client = c
+	return client, nil
+}
+
+// connect returns the connection dialed by the constructor on first use. Afterwards every
+// call is a reconnect and is retried according to the reconnect policy.
+func (c *SignalRClient) connect() (signalr.Connection, error) {
+	c.mutex.Lock()
+	conn := c.pending
+	c.pending = nil
+	c.mutex.Unlock()
+
+	if conn != nil {
+		return conn, nil
+	}
+	return c.reconnect()
+}
+
+// dial creates a new HTTP connection to the hub authenticated with the current token.
+func (c *SignalRClient) dial() (signalr.Connection, error) {
+	c.mutex.RLock()
+	cfg := c.config
+	token := c.token
+	c.mutex.RUnlock()
+
+	conn, connCancel, err := dialHub(c.ctx, cfg, token)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	c.connCancel = connCancel
+	c.mutex.Unlock()
+	return conn, nil
+}
+
+// dialHub creates a new HTTP connection to cfg.hubURL authenticated with token.
+// The connection gets its own context derived from ctx; cancelling it drops the connection.
+func dialHub(ctx context.Context, cfg signalRConfig, token string) (signalr.Connection, context.CancelFunc, error) {
 	// Configure the SignalR hub URL
-	hubURL := "https://rtc.thefuturesdesk.projectx.com/hubs/market"
-	parsedURL, err := url.Parse(hubURL)
+	parsedURL, err := url.Parse(cfg.hubURL)
 	if err != nil {
-		return nil, fmt.Errorf("failed to parse hub URL: %v", err)
+		return nil, nil, fmt.Errorf("failed to parse hub URL: %v", err)
 	}
 
-	// Add JWT token to query parameters for authentication
+	// Merge extra query parameters and add the JWT token for authentication
 	q := parsedURL.Query()
-	q.Add("access_token", jwtToken)
+	for key, values := range cfg.query {
+		for _, v := range values {
+			q.Add(key, v)
+		}
+	}
+	q.Set("access_token", token)
 	parsedURL.RawQuery = q.Encode()
 
+	// Each connection gets its own context so it can be dropped without stopping the client
+	connCtx, connCancel := context.WithCancel(ctx)
+
+	// The connection lives on connCtx, so a context.WithTimeout would also end an
+	// established connection. Cancel it from a timer instead, stopped once connected.
+	var timer *time.Timer
+	if cfg.negotiate > 0 {
+		timer = time.AfterFunc(cfg.negotiate, connCancel)
+	}
+
 	// Create HTTP connection with WebSocket transport
 	// This sets up the underlying WebSocket connection with proper headers
-	conn, err := signalr.NewHTTPConnection(ctx, parsedURL.String(),
+	conn, err := signalr.NewHTTPConnection(connCtx, parsedURL.String(),
 		signalr.WithTransports(signalr.TransportWebSockets),
 		signalr.WithHTTPHeaders(func() http.Header {
-			h := http.Header{}
-			h.Set("Authorization", "Bearer "+jwtToken)
+			h := cfg.headers.Clone()
+			h.Set("Authorization", "Bearer "+token)
 			return h
 		}))
-	if err != nil {
-		cancel()
-		return nil, fmt.Errorf("failed to create SignalR connection: %v", err)
+	timedOut := timer != nil && !timer.Stop()
+	if timedOut {
+		connCancel()
+		return nil, nil, fmt.Errorf("failed to create SignalR connection: negotiation with %s timed out after %s", parsedURL.Host, cfg.negotiate)
 	}
-
-	// Create SignalR client with the HTTP connection and register this instance as the message receiver
-	c, err := signalr.NewClient(ctx,
-		signalr.WithConnection(conn),
-		signalr.WithReceiver(client))
 	if err != nil {
-		cancel()
-		return nil, fmt.Errorf("failed to create SignalR client: %v", err)
+		connCancel()
+		return nil, nil, fmt.Errorf("failed to create SignalR connection: %v", err)
 	}
+	return conn, connCancel, nil
+}
 
-	client.client = c
-	return client, nil
+// UpdateToken replaces the JWT used to authenticate with the hub.
+// The token baked into an established connection cannot be changed, so the new token takes
+// effect on the next reconnect. Pass reconnect=true to drop the current connection and
+// reconnect immediately, e.g. right after the REST Client has logged in again.
+func (c *SignalRClient) UpdateToken(jwtToken string, reconnect bool) {
+	c.mutex.Lock()
+	c.token = jwtToken
+	connCancel := c.connCancel
+	c.mutex.Unlock()
+
+	if reconnect && connCancel != nil {
+		connCancel()
+	}
 }
 
 // OnConnected is called when the SignalR connection is established.
@@ -90,22 +317,29 @@ func NewSignalRClient(jwtToken string, marketHandler MarketDataHandler) (*Signal
 func (c *SignalRClient) OnConnected(connectionID string) {
 	c.mutex.Lock()
 	c.isConnected = true
+	c.reconnecting = false
+	c.connectionID = connectionID
+	contracts := make([]string, 0, len(c.subscriptions))
+	for contractID := range c.subscriptions {
+		contracts = append(contracts, contractID)
+	}
 	c.mutex.Unlock()
 	log.Printf("SignalR connected with ID: %s", connectionID)
 
-	// Resubscribe to all contracts that were previously subscribed
-	for contractID := range c.subscriptions {
-		if err := c.Subscribe(contractID); err != nil {
+	// Resubscribe to the streams that were previously subscribed for each contract
+	for _, contractID := range contracts {
+		if err := c.resubscribe(contractID); err != nil {
 			log.Printf("Failed to resubscribe to %s: %v", contractID, err)
 		}
 	}
 }
 
 // OnDisconnected is called when the SignalR connection is lost.
-// It updates the connection state and increments the reconnection counter.
+// It updates the connection state, clears the connection ID and increments the reconnection counter.
 func (c *SignalRClient) OnDisconnected(connectionID string) {
 	c.mutex.Lock()
 	c.isConnected = false
+	c.connectionID = ""
 	c.reconnectCount++
 	c.mutex.Unlock()
 	log.Printf("SignalR disconnected (attempt %d)", c.reconnectCount)
@@ -114,21 +348,56 @@ func (c *SignalRClient) OnDisconnected(connectionID string) {
 // OnGatewayQuote handles incoming quote messages from the SignalR hub.
 // It forwards the quote data to the market data handler.
 func (c *SignalRClient) OnGatewayQuote(contractID string, data map[string]interface{}) {
+	c.received("GatewayQuote", contractID, data)
 	c.marketHandler.OnQuote(contractID, data)
 }
 
 // OnGatewayTrade handles incoming trade messages from the SignalR hub.
 // It forwards the trade data to the market data handler.
 func (c *SignalRClient) OnGatewayTrade(contractID string, data map[string]interface{}) {
+	c.received("GatewayTrade", contractID, data)
 	c.marketHandler.OnTrade(contractID, data)
 }
 
 // OnGatewayDepth handles incoming market depth messages from the SignalR hub.
 // It forwards the depth data to the market data handler.
 func (c *SignalRClient) OnGatewayDepth(contractID string, data map[string]interface{}) {
+	c.received("GatewayDepth", contractID, data)
 	c.marketHandler.OnDepth(contractID, data)
 }
 
+// received counts the message, records when it was received for the contract and passes
+// it on to the raw message tap, if any.
+func (c *SignalRClient) received(method, contractID string, data map[string]interface{}) {
+	c.mutex.Lock()
+	c.lastUpdate[contractID] = time.Now()
+	stats := c.stats[contractID]
+	switch method {
+	case "GatewayQuote":
+		stats.Quotes++
+	case "GatewayTrade":
+		stats.Trades++
+	case "GatewayDepth":
+		stats.Depth++
+	}
+	c.stats[contractID] = stats
+	tap := c.rawTap
+	c.mutex.Unlock()
+
+	if tap != nil {
+		tap(method, []interface{}{contractID, data})
+	}
+}
+
+// OnRawMessage registers a tap called with the hub method name and arguments of every
+// incoming market message, before it is dispatched to the handler. Use it to inspect
+// payloads that fail to parse. Pass nil to remove the tap.
+func (c *SignalRClient) OnRawMessage(fn func(method string, args []interface{})) {
+	c.mutex.Lock()
+	c.rawTap = fn
+	c.mutex.Unlock()
+}
+
 // Start initiates the SignalR connection.
 // This begins the WebSocket connection and message processing.
 func (c *SignalRClient) Start() error {
@@ -142,11 +411,12 @@ func (c *SignalRClient) Stop() error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
-	// Unsubscribe from all contracts before stopping
-	for contractID := range c.subscriptions {
-		if err := c.unsubscribe(contractID); err != nil {
+	// Unsubscribe from all contracts before stopping, whoever subscribed them
+	for contractID, refs := range c.subscriptions {
+		if err := c.sendStreams(contractID, refs.streams(), false); err != nil {
 			log.Printf("Failed to unsubscribe from %s: %v", contractID, err)
 		}
+		delete(c.subscriptions, contractID)
 	}
 
 	c.cancel() // Cancel the context to stop all operations
@@ -155,9 +425,74 @@ func (c *SignalRClient) Stop() error {
 	return nil
 }
 
+// SubscribeOptions selects the market data streams of a contract.
+type SubscribeOptions struct {
+	Quotes bool // Best bid/ask and last price updates
+	Trades bool // Executed trades
+	Depth  bool // Market depth updates
+}
+
+// AllStreams selects quotes, trades and market depth.
+var AllStreams = SubscribeOptions{Quotes: true, Trades: true, Depth: true}
+
+// marketStreams lists the hub methods for each stream selectable through SubscribeOptions.
+var marketStreams = []struct {
+	name        string                        // Stream name used in error messages
+	subscribe   string                        // Hub method to subscribe
+	unsubscribe string                        // Hub method to unsubscribe
+	field       func(*SubscribeOptions) *bool // Selects the stream's flag
+}{
+	{"quotes", "SubscribeContractQuotes", "UnsubscribeContractQuotes", func(o *SubscribeOptions) *bool { return &o.Quotes }},
+	{"trades", "SubscribeContractTrades", "UnsubscribeContractTrades", func(o *SubscribeOptions) *bool { return &o.Trades }},
+	{"market depth", "SubscribeContractMarketDepth", "UnsubscribeContractMarketDepth", func(o *SubscribeOptions) *bool { return &o.Depth }},
+}
+
 // Subscribe adds a subscription for the specified contract.
 // It sends subscription requests for quotes, trades, and market depth.
 func (c *SignalRClient) Subscribe(contractID string) error {
+	return c.SubscribeStreams(contractID, AllStreams)
+}
+
+// validateContract checks contractID with the configured lookup, if any. It is called
+// without the lock held since the lookup may make a REST call.
+func (c *SignalRClient) validateContract(contractID string) error {
+	if c.config.lookup == nil {
+		return nil
+	}
+	_, err := c.config.lookup(contractID)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("failed to subscribe to %s: %w", contractID, ErrUnknownContract)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to validate contract %s: %w", contractID, err)
+	}
+	return nil
+}
+
+// streamRefs counts the subscribers of each stream of a contract, in marketStreams order.
+type streamRefs [3]int
+
+// streams returns the streams with at least one subscriber.
+func (r streamRefs) streams() SubscribeOptions {
+	var opts SubscribeOptions
+	for i, stream := range marketStreams {
+		*stream.field(&opts) = r[i] > 0
+	}
+	return opts
+}
+
+// SubscribeStreams subscribes to the selected streams of the specified contract,
+// in addition to any streams already subscribed.
+//
+// Subscriptions are reference counted per stream, so independent components can share
+// one client: the hub is only asked to subscribe a stream for its first subscriber, and
+// each SubscribeStreams or Subscribe call must be matched by an UnsubscribeStreams or
+// Unsubscribe call before the stream is unsubscribed from the hub.
+func (c *SignalRClient) SubscribeStreams(contractID string, opts SubscribeOptions) error {
+	if err := c.validateContract(contractID); err != nil {
+		return err
+	}
+
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
 
@@ -165,63 +500,134 @@ func (c *SignalRClient) Subscribe(contractID string) error {
 		return fmt.Errorf("not connected to SignalR hub")
 	}
 
-	// Subscribe to quotes
-	ch := c.client.Send("SubscribeContractQuotes", contractID)
-	if err := <-ch; err != nil {
-		return fmt.Errorf("failed to subscribe to quotes: %v", err)
+	// Record each stream as it succeeds so a partial failure leaves accurate state
+	refs := c.subscriptions[contractID]
+	for i, stream := range marketStreams {
+		if !*stream.field(&opts) {
+			continue
+		}
+		if refs[i] == 0 {
+			ch := c.client.Send(stream.subscribe, contractID)
+			if err := <-ch; err != nil {
+				return fmt.Errorf("failed to subscribe to %s: %v", stream.name, err)
+			}
+		}
+		refs[i]++
+		c.subscriptions[contractID] = refs
 	}
+	return nil
+}
 
-	// Subscribe to trades
-	ch = c.client.Send("SubscribeContractTrades", contractID)
-	if err := <-ch; err != nil {
-		return fmt.Errorf("failed to subscribe to trades: %v", err)
-	}
+// resubscribe asks the hub again for every stream of the contract that has subscribers,
+// after a reconnect. Reference counts are left unchanged.
+func (c *SignalRClient) resubscribe(contractID string) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
 
-	// Subscribe to market depth
-	ch = c.client.Send("SubscribeContractMarketDepth", contractID)
-	if err := <-ch; err != nil {
-		return fmt.Errorf("failed to subscribe to market depth: %v", err)
+	if !c.isConnected {
+		return fmt.Errorf("not connected to SignalR hub")
+	}
+	refs, ok := c.subscriptions[contractID]
+	if !ok {
+		return nil
 	}
+	return c.sendStreams(contractID, refs.streams(), true)
+}
 
-	c.subscriptions[contractID] = true
+// sendStreams sends the hub subscribe or unsubscribe method of each selected stream,
+// without touching reference counts. It must be called with the lock held.
+func (c *SignalRClient) sendStreams(contractID string, opts SubscribeOptions, subscribe bool) error {
+	for _, stream := range marketStreams {
+		if !*stream.field(&opts) {
+			continue
+		}
+		method, action := stream.unsubscribe, "unsubscribe from"
+		if subscribe {
+			method, action = stream.subscribe, "subscribe to"
+		}
+		ch := c.client.Send(method, contractID)
+		if err := <-ch; err != nil {
+			return fmt.Errorf("failed to %s %s: %v", action, stream.name, err)
+		}
+	}
 	return nil
 }
 
-// unsubscribe removes a subscription for the specified contract.
-// It sends unsubscribe requests for quotes, trades, and market depth.
-func (c *SignalRClient) unsubscribe(contractID string) error {
+// unsubscribeStreams releases one reference to each selected stream of the specified
+// contract, unsubscribing from the hub the streams left without subscribers. Streams
+// that are not subscribed are ignored. It reports whether the contract has no
+// subscribed streams left.
+func (c *SignalRClient) unsubscribeStreams(contractID string, opts SubscribeOptions) (bool, error) {
 	if !c.isConnected {
-		return fmt.Errorf("not connected to SignalR hub")
+		return false, fmt.Errorf("not connected to SignalR hub")
 	}
 
-	// Unsubscribe from quotes
-	ch := c.client.Send("UnsubscribeContractQuotes", contractID)
-	if err := <-ch; err != nil {
-		return fmt.Errorf("failed to unsubscribe from quotes: %v", err)
+	refs, ok := c.subscriptions[contractID]
+	if !ok {
+		return false, nil
 	}
-
-	// Unsubscribe from trades
-	ch = c.client.Send("UnsubscribeContractTrades", contractID)
-	if err := <-ch; err != nil {
-		return fmt.Errorf("failed to unsubscribe from trades: %v", err)
+	for i, stream := range marketStreams {
+		if !*stream.field(&opts) || refs[i] == 0 {
+			continue
+		}
+		if refs[i] == 1 {
+			ch := c.client.Send(stream.unsubscribe, contractID)
+			if err := <-ch; err != nil {
+				return false, fmt.Errorf("failed to unsubscribe from %s: %v", stream.name, err)
+			}
+		}
+		refs[i]--
+		c.subscriptions[contractID] = refs
 	}
 
-	// Unsubscribe from market depth
-	ch = c.client.Send("UnsubscribeContractMarketDepth", contractID)
-	if err := <-ch; err != nil {
-		return fmt.Errorf("failed to unsubscribe from market depth: %v", err)
+	if refs == (streamRefs{}) {
+		delete(c.subscriptions, contractID)
+		return true, nil
 	}
+	return false, nil
+}
 
-	delete(c.subscriptions, contractID)
-	return nil
+// unsubscribe releases one reference to every stream of the specified contract.
+func (c *SignalRClient) unsubscribe(contractID string) (bool, error) {
+	return c.unsubscribeStreams(contractID, AllStreams)
 }
 
-// Unsubscribe safely removes a subscription for the specified contract.
-// It acquires a lock before calling unsubscribe to ensure thread safety.
+// Unsubscribe releases the streams taken by a matching Subscribe call, unsubscribing
+// from the hub those no other subscriber holds. Once the contract has no streams left,
+// a BarAggregator handler for it is flushed outside the lock.
 func (c *SignalRClient) Unsubscribe(contractID string) error {
+	c.mutex.Lock()
+	removed, err := c.unsubscribe(contractID)
+	c.mutex.Unlock()
+
+	if removed {
+		flushContract(c.marketHandler, contractID)
+	}
+	return err
+}
+
+// flushContract flushes the bar forming in handler, or in any handler of a MultiHandler,
+// when it aggregates the given contract.
+func flushContract(handler MarketDataHandler, contractID string) {
+	switch h := handler.(type) {
+	case BarAggregator:
+		if h.ContractID() == contractID {
+			h.Flush()
+		}
+	case MultiHandler:
+		for _, inner := range h {
+			flushContract(inner, contractID)
+		}
+	}
+}
+
+// UnsubscribeStreams releases only the selected streams of the specified contract, e.g. to
+// drop depth while keeping quotes and trades. The remaining streams are restored on reconnect.
+func (c *SignalRClient) UnsubscribeStreams(contractID string, opts SubscribeOptions) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
-	return c.unsubscribe(contractID)
+	_, err := c.unsubscribeStreams(contractID, opts)
+	return err
 }
 
 // IsConnected returns the current connection state.
@@ -231,3 +637,44 @@ func (c *SignalRClient) IsConnected() bool {
 	defer c.mutex.RUnlock()
 	return c.isConnected
 }
+
+// ConnectionID returns the connection ID assigned by the hub, or an empty string when disconnected.
+// Include it in support requests to the broker so they can locate the session.
+func (c *SignalRClient) ConnectionID() string {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.connectionID
+}
+
+// LastUpdateTimes returns the time of the last message received for each contract.
+// The returned map is a copy and safe to modify.
+func (c *SignalRClient) LastUpdateTimes() map[string]time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	times := make(map[string]time.Time, len(c.lastUpdate))
+	for contractID, t := range c.lastUpdate {
+		times[contractID] = t
+	}
+	return times
+}
+
+// StreamStats counts the messages received for one contract, per stream.
+type StreamStats struct {
+	Quotes int64 // Quote messages received
+	Trades int64 // Trade messages received
+	Depth  int64 // Market depth messages received
+}
+
+// Stats returns the message counts per contract since the client was created, e.g. to
+// verify that a depth subscription is delivering. The returned map is a copy.
+func (c *SignalRClient) Stats() map[string]StreamStats {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	stats := make(map[string]StreamStats, len(c.stats))
+	for contractID, s := range c.stats {
+		stats[contractID] = s
+	}
+	return stats
+}
@@ -0,0 +1,159 @@
+package projectx
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/philippseith/signalr"
+)
+
+// ReconnectPolicy controls how a SignalRClient or UserHubClient re-establishes a lost
+// connection.
+// Attempts are spaced by an exponentially growing delay with up to 20% random jitter.
+type ReconnectPolicy struct {
+	MaxAttempts  int           // Attempts before giving up; 0 retries forever
+	InitialDelay time.Duration // Delay before the first attempt
+	MaxDelay     time.Duration // Upper bound on the delay between attempts
+
+	// Jitter returns the random delay added to the backoff delay d. Nil adds up to 20%
+	// of d from math/rand's randomly seeded source. Set it, e.g. to RandJitter with a
+	// seeded *rand.Rand, to make backoff timing deterministic in tests.
+	Jitter func(d time.Duration) time.Duration
+}
+
+// RandJitter returns a Jitter function adding up to fraction of the delay, drawn from r.
+// r is used under a lock, so the function is safe for concurrent use.
+func RandJitter(r *rand.Rand, fraction float64) func(d time.Duration) time.Duration {
+	var mutex sync.Mutex
+	return func(d time.Duration) time.Duration {
+		mutex.Lock()
+		defer mutex.Unlock()
+		return time.Duration(r.Float64() * fraction * float64(d))
+	}
+}
+
+// DefaultReconnectPolicy retries forever, backing off from 1 second up to 30 seconds.
+var DefaultReconnectPolicy = ReconnectPolicy{
+	InitialDelay: time.Second,
+	MaxDelay:     30 * time.Second,
+}
+
+// WithReconnectPolicy sets the policy used after the connection is lost. It applies to
+// the market hub and the user hub alike.
+func WithReconnectPolicy(policy ReconnectPolicy) SignalROption {
+	return func(cfg *signalRConfig) {
+		cfg.reconnect = policy
+	}
+}
+
+// delay returns the wait before the given attempt, starting at 1.
+func (p ReconnectPolicy) delay(attempt int) time.Duration {
+	d := p.InitialDelay
+	for i := 1; i < attempt && (p.MaxDelay <= 0 || d < p.MaxDelay); i++ {
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		d = p.MaxDelay
+	}
+	if p.Jitter != nil {
+		return d + p.Jitter(d)
+	}
+	return d + time.Duration(rand.Float64()*0.2*float64(d))
+}
+
+// OnReconnectFailed registers a callback fired once when the reconnect policy gives up.
+// After that the client is stopped and must be replaced, so the callback is the place to
+// alert or exit. It receives the error from the last attempt.
+func (c *SignalRClient) OnReconnectFailed(fn func(lastErr error)) {
+	c.mutex.Lock()
+	c.onGiveUp = fn
+	c.mutex.Unlock()
+}
+
+// reconnect dials the hub until it succeeds, the client is stopped, or the policy gives up.
+func (c *SignalRClient) reconnect() (signalr.Connection, error) {
+	c.mutex.RLock()
+	policy := c.config.reconnect
+	gaveUp := c.gaveUp
+	c.mutex.RUnlock()
+
+	if gaveUp {
+		return nil, fmt.Errorf("reconnection abandoned")
+	}
+
+	// Cleared by OnConnected once the new connection completes its handshake
+	c.setReconnecting(true)
+
+	conn, err := redial(c.ctx, policy, "SignalR", c.dial)
+	if err == nil {
+		return conn, nil
+	}
+	if c.ctx.Err() != nil {
+		c.setReconnecting(false)
+		return nil, c.ctx.Err()
+	}
+	c.giveUp(err)
+	return nil, fmt.Errorf("reconnection failed after %d attempts: %w", policy.MaxAttempts, err)
+}
+
+// redial calls dial with the delays of policy until it succeeds, ctx is done or the
+// policy gives up. It returns ctx's error or the error of the last attempt; hub names
+// the hub in log messages.
+func redial(ctx context.Context, policy ReconnectPolicy, hub string, dial func() (signalr.Connection, error)) (signalr.Connection, error) {
+	var lastErr error
+	for attempt := 1; policy.MaxAttempts == 0 || attempt <= policy.MaxAttempts; attempt++ {
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(policy.delay(attempt)):
+		}
+
+		conn, err := dial()
+		if err == nil {
+			return conn, nil
+		}
+		lastErr = err
+		log.Printf("%s reconnect attempt %d failed: %v", hub, attempt, err)
+	}
+	if lastErr == nil {
+		lastErr = fmt.Errorf("no reconnect attempts allowed")
+	}
+	return nil, lastErr
+}
+
+// giveUp marks reconnection as abandoned, stops the client and fires the callback exactly once.
+func (c *SignalRClient) giveUp(lastErr error) {
+	c.mutex.Lock()
+	if c.gaveUp {
+		c.mutex.Unlock()
+		return
+	}
+	c.gaveUp = true
+	c.reconnecting = false
+	fn := c.onGiveUp
+	c.mutex.Unlock()
+
+	log.Printf("SignalR giving up on reconnection: %v", lastErr)
+	c.cancel()
+	if fn != nil {
+		fn(lastErr)
+	}
+}
+
+// IsReconnecting reports whether the client is re-establishing a lost connection, so
+// a UI can show "reconnecting" distinct from connected and disconnected.
+func (c *SignalRClient) IsReconnecting() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.reconnecting
+}
+
+func (c *SignalRClient) setReconnecting(reconnecting bool) {
+	c.mutex.Lock()
+	c.reconnecting = reconnecting
+	c.mutex.Unlock()
+}
@@ -0,0 +1,197 @@
+package projectx
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/philippseith/signalr"
+)
+
+// fakeHub is a connected hub client that records the methods sent to it. Methods other
+// than Send are not used by subscriptions and panic through the nil embedded client.
+type fakeHub struct {
+	signalr.Client
+
+	mutex sync.Mutex
+	sent  []string // "Method contractID", in send order
+}
+
+func (h *fakeHub) Send(method string, arguments ...interface{}) <-chan error {
+	h.mutex.Lock()
+	h.sent = append(h.sent, fmt.Sprintf("%s %v", method, arguments[0]))
+	h.mutex.Unlock()
+
+	ch := make(chan error, 1)
+	ch <- nil
+	return ch
+}
+
+// count returns how often method was sent for contractID.
+func (h *fakeHub) count(method, contractID string) int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	n := 0
+	for _, s := range h.sent {
+		if s == method+" "+contractID {
+			n++
+		}
+	}
+	return n
+}
+
+func newTestSignalRClient(hub *fakeHub) *SignalRClient {
+	return &SignalRClient{
+		client:        hub,
+		subscriptions: make(map[string]streamRefs),
+		lastUpdate:    make(map[string]time.Time),
+		stats:         make(map[string]StreamStats),
+		isConnected:   true,
+		config:        newSignalRConfig(DefaultMarketHubURL, nil),
+	}
+}
+
+func TestSubscribeStreamsRefCount(t *testing.T) {
+	hub := &fakeHub{}
+	c := newTestSignalRClient(hub)
+	const id = "CON.F.US.EP.H24"
+	quotes := SubscribeOptions{Quotes: true}
+	trades := SubscribeOptions{Trades: true}
+
+	steps := []struct {
+		name string
+		do   func() error
+		want map[string]int // Cumulative sends per hub method
+	}{
+		{"first quotes", func() error { return c.SubscribeStreams(id, quotes) },
+			map[string]int{"SubscribeContractQuotes": 1}},
+		{"second quotes", func() error { return c.SubscribeStreams(id, quotes) },
+			map[string]int{"SubscribeContractQuotes": 1}},
+		{"all streams", func() error { return c.Subscribe(id) },
+			map[string]int{"SubscribeContractQuotes": 1, "SubscribeContractTrades": 1, "SubscribeContractMarketDepth": 1}},
+		{"release all streams", func() error { return c.Unsubscribe(id) },
+			map[string]int{"UnsubscribeContractQuotes": 0, "UnsubscribeContractTrades": 1, "UnsubscribeContractMarketDepth": 1}},
+		{"release one quotes", func() error { return c.UnsubscribeStreams(id, quotes) },
+			map[string]int{"UnsubscribeContractQuotes": 0}},
+		{"release last quotes", func() error { return c.UnsubscribeStreams(id, quotes) },
+			map[string]int{"SubscribeContractQuotes": 1, "UnsubscribeContractQuotes": 1}},
+		{"release unsubscribed trades", func() error { return c.UnsubscribeStreams(id, trades) },
+			map[string]int{"UnsubscribeContractTrades": 1}},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		for method, want := range step.want {
+			if got := hub.count(method, id); got != want {
+				t.Fatalf("after %s: %s sent %d times, want %d", step.name, method, got, want)
+			}
+		}
+	}
+	if len(c.subscriptions) != 0 {
+		t.Errorf("subscriptions left: %v", c.subscriptions)
+	}
+}
+
+// TestSubscribeStreamsOverlap overlaps subscribers of the same streams. Run with -race.
+func TestSubscribeStreamsOverlap(t *testing.T) {
+	hub := &fakeHub{}
+	c := newTestSignalRClient(hub)
+	const id = "CON.F.US.EP.H24"
+
+	// A long-lived subscriber keeps the streams up while others come and go
+	if err := c.Subscribe(id); err != nil {
+		t.Fatal(err)
+	}
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 100)
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			opts := SubscribeOptions{Quotes: true, Trades: i%2 == 0, Depth: i%3 == 0}
+			if err := c.SubscribeStreams(id, opts); err != nil {
+				errs <- err
+				return
+			}
+			if err := c.UnsubscribeStreams(id, opts); err != nil {
+				errs <- err
+			}
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		t.Fatal(err)
+	}
+
+	for _, stream := range marketStreams {
+		if got := hub.count(stream.subscribe, id); got != 1 {
+			t.Errorf("%s sent %d times, want 1", stream.subscribe, got)
+		}
+		if got := hub.count(stream.unsubscribe, id); got != 0 {
+			t.Errorf("%s sent %d times while subscribed, want 0", stream.unsubscribe, got)
+		}
+	}
+
+	if err := c.Unsubscribe(id); err != nil {
+		t.Fatal(err)
+	}
+	for _, stream := range marketStreams {
+		if got := hub.count(stream.unsubscribe, id); got != 1 {
+			t.Errorf("%s sent %d times, want 1", stream.unsubscribe, got)
+		}
+	}
+}
+
+// TestSubscribeStreamsChurn overlaps subscribers without a long-lived one, so streams go
+// up and down repeatedly. Each stream's hub calls must alternate subscribe, unsubscribe.
+func TestSubscribeStreamsChurn(t *testing.T) {
+	hub := &fakeHub{}
+	c := newTestSignalRClient(hub)
+	const id = "CON.F.US.EP.H24"
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := c.Subscribe(id); err != nil {
+				t.Error(err)
+				return
+			}
+			if err := c.Unsubscribe(id); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+
+	for _, stream := range marketStreams {
+		subscribed := false
+		for _, s := range hub.sent {
+			method, _, _ := strings.Cut(s, " ")
+			switch method {
+			case stream.subscribe:
+				if subscribed {
+					t.Fatalf("%s sent twice without an unsubscribe", method)
+				}
+				subscribed = true
+			case stream.unsubscribe:
+				if !subscribed {
+					t.Fatalf("%s sent without a subscribe", method)
+				}
+				subscribed = false
+			}
+		}
+		if subscribed {
+			t.Errorf("%s left subscribed", stream.name)
+		}
+	}
+	if len(c.subscriptions) != 0 {
+		t.Errorf("subscriptions left: %v", c.subscriptions)
+	}
+}
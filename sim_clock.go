@@ -0,0 +1,88 @@
+package projectx
+
+import (
+	"sync"
+	"time"
+)
+
+// SimClock is a manually driven clock for backtests. Pass its Now method to the
+// WithClock methods of MarketDataManager, HeikinAshiAggregator and SessionStats, and the
+// clock itself to BacktestFeed.WithClock, so replayed ticks and bar rollover share one
+// time line and a replay gives the same bars every run.
+//
+// The clock only moves forward. With a speed set, AdvanceTo also waits in real time,
+// e.g. speed 10 replays a minute of recorded data in six seconds.
+//
+// Bars only close when an update arrives, so register the aggregators' Tick methods
+// with OnAdvance to close bars whose period elapses while no ticks are replayed.
+type SimClock struct {
+	mutex     sync.RWMutex
+	now       time.Time
+	speed     float64
+	listeners []func(now time.Time)
+}
+
+// NewSimClock returns a clock reading start. Replay runs as fast as possible until a
+// speed is set.
+func NewSimClock(start time.Time) *SimClock {
+	return &SimClock{now: start}
+}
+
+// Now returns the simulated time. It has the signature expected by the WithClock methods.
+func (c *SimClock) Now() time.Time {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.now
+}
+
+// OnAdvance registers fn to be called with the new time whenever the clock moves
+// forward, e.g. MarketDataManager.Tick. Listeners run in registration order on the
+// goroutine advancing the clock.
+func (c *SimClock) OnAdvance(fn func(now time.Time)) *SimClock {
+	c.mutex.Lock()
+	c.listeners = append(c.listeners, fn)
+	c.mutex.Unlock()
+	return c
+}
+
+// SetSpeed sets how many times faster than real time AdvanceTo proceeds. Zero or
+// less disables waiting.
+func (c *SimClock) SetSpeed(multiplier float64) {
+	c.mutex.Lock()
+	c.speed = multiplier
+	c.mutex.Unlock()
+}
+
+// Advance moves the clock forward by d, waiting as AdvanceTo does.
+func (c *SimClock) Advance(d time.Duration) {
+	c.AdvanceTo(c.Now().Add(d))
+}
+
+// AdvanceTo moves the clock forward to t, first waiting the elapsed simulated time
+// divided by the speed when one is set, then notifies the OnAdvance listeners. Times
+// before the current time are ignored, so slightly out-of-order records do not move
+// the clock back.
+func (c *SimClock) AdvanceTo(t time.Time) {
+	c.mutex.RLock()
+	gap, speed := t.Sub(c.now), c.speed
+	c.mutex.RUnlock()
+	if gap <= 0 {
+		return
+	}
+	if speed > 0 {
+		time.Sleep(time.Duration(float64(gap) / speed))
+	}
+
+	c.mutex.Lock()
+	if !t.After(c.now) {
+		c.mutex.Unlock()
+		return
+	}
+	c.now = t
+	listeners := c.listeners
+	c.mutex.Unlock()
+
+	for _, fn := range listeners {
+		fn(t)
+	}
+}
@@ -0,0 +1,39 @@
+package projectx
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSimClockClosesQuietBar(t *testing.T) {
+	const id = "CON.F.US.EP.H24"
+	start := time.Date(2024, 1, 2, 14, 30, 0, 0, time.UTC)
+	clock := NewSimClock(start)
+
+	var bars []HistoryBar
+	m := NewMarketDataManager(id, 1, func(bar HistoryBar) { bars = append(bars, bar) }).WithClock(clock.Now)
+	clock.OnAdvance(m.Tick)
+
+	m.OnTrade(id, trade(100, 1, OrderSideBidBuy))
+	clock.Advance(30 * time.Second)
+	if len(bars) != 0 {
+		t.Fatalf("bar emitted before its period elapsed: %+v", bars)
+	}
+
+	clock.Advance(45 * time.Second)
+	if len(bars) != 1 {
+		t.Fatalf("got %d bars after the period elapsed without ticks, want 1", len(bars))
+	}
+	if !bars[0].Time.Equal(start) || bars[0].Close != 100 || bars[0].Vol != 1 {
+		t.Errorf("emitted %+v", bars[0])
+	}
+	if _, ok := m.CurrentBar(); ok {
+		t.Error("closed bar still being built")
+	}
+
+	// Further advances with no bar open emit nothing
+	clock.Advance(5 * time.Minute)
+	if len(bars) != 1 {
+		t.Errorf("got %d bars, want 1", len(bars))
+	}
+}
@@ -0,0 +1,201 @@
+package projectx
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// tickEpsilon absorbs floating point error when checking tick alignment.
+const tickEpsilon = 1e-6
+
+// RoundMode selects the direction RoundToTick rounds in.
+type RoundMode int
+
+const (
+	RoundNearest RoundMode = iota // Nearest tick, halves away from zero
+	RoundDown                     // Highest tick at or below the price
+	RoundUp                       // Lowest tick at or above the price
+)
+
+// RoundToTick rounds price to a multiple of tickSize in the given mode. Use RoundUp for
+// buy stops and RoundDown for sell stops so the stop stays on the far side of the market.
+// Prices already within floating point error of a tick snap to it in every mode.
+// A non-positive tickSize returns price unchanged.
+func RoundToTick(price, tickSize float64, mode RoundMode) float64 {
+	if tickSize <= 0 {
+		return price
+	}
+	ticks := price / tickSize
+	nearest := math.Round(ticks)
+	if math.Abs(ticks-nearest) < tickEpsilon {
+		return nearest * tickSize
+	}
+	switch mode {
+	case RoundDown:
+		return math.Floor(ticks) * tickSize
+	case RoundUp:
+		return math.Ceil(ticks) * tickSize
+	}
+	// Nudge away from zero so halves off by floating point error, e.g. 0.35 / 0.1,
+	// still round away from zero
+	return math.Round(ticks+math.Copysign(tickEpsilon, ticks)) * tickSize
+}
+
+// IsTickAligned reports whether price is a multiple of tickSize.
+func IsTickAligned(price, tickSize float64) bool {
+	if tickSize <= 0 {
+		return true
+	}
+	ticks := price / tickSize
+	return math.Abs(ticks-math.Round(ticks)) < tickEpsilon
+}
+
+// ValidateTickPrices returns an error naming the first of the given prices that is
+// not aligned to the contract's tick size. Nil prices are skipped.
+func ValidateTickPrices(contract Contract, limitPrice, stopPrice, trailPrice *float64) error {
+	prices := []struct {
+		name  string
+		price *float64
+	}{
+		{"limit", limitPrice},
+		{"stop", stopPrice},
+		{"trail", trailPrice},
+	}
+	for _, p := range prices {
+		if p.price != nil && !IsTickAligned(*p.price, contract.TickSize) {
+			return fmt.Errorf("%s price %v is not a multiple of tick size %v for %s",
+				p.name, *p.price, contract.TickSize, contract.ID)
+		}
+	}
+	return nil
+}
+
+// ModifyOrderChecked is ModifyOrder with client-side validation that the new prices
+// align to the contract's tick size, so misaligned prices fail before reaching the gateway.
+func (c *Client) ModifyOrderChecked(contract Contract, accountId, orderId int, size *int, limitPrice, stopPrice, trailPrice *float64) error {
+	if err := ValidateTickPrices(contract, limitPrice, stopPrice, trailPrice); err != nil {
+		return fmt.Errorf("order modify failed: %w", err)
+	}
+	return c.ModifyOrder(accountId, orderId, size, limitPrice, stopPrice, trailPrice)
+}
+
+// CalcProtectivePrices returns the stop and target for an entry on side, stopTicks and
+// targetTicks away: below and above the entry for a buy, above and below it for a sell.
+// The entry is first rounded to the nearest tick, so both prices are tick-aligned. Use
+// them for the legs of a bracket placed with PlaceOrderGroup.
+func CalcProtectivePrices(entry float64, side Side, stopTicks, targetTicks int, tickSize float64) (stop, target float64) {
+	if tickSize <= 0 {
+		return entry, entry
+	}
+	base := math.Round(entry / tickSize)
+	sign := float64(side.Sign())
+	stop = (base - sign*float64(stopTicks)) * tickSize
+	target = (base + sign*float64(targetTicks)) * tickSize
+	return stop, target
+}
+
+// TickDecimals returns the number of decimals needed to show prices in multiples of
+// tickSize, e.g. 2 for 0.25 and 4 for 0.0001. It is capped at 10.
+func TickDecimals(tickSize float64) int {
+	if tickSize <= 0 {
+		return 2
+	}
+	scaled := tickSize
+	for d := 0; d < 10; d++ {
+		if math.Abs(scaled-math.Round(scaled)) < tickEpsilon*scaled {
+			return d
+		}
+		scaled *= 10
+	}
+	return 10
+}
+
+// FormatPrice formats price with the number of decimals implied by the contract's tick size.
+func (c Contract) FormatPrice(price float64) string {
+	return strconv.FormatFloat(price, 'f', TickDecimals(c.TickSize), 64)
+}
+
+// NormalizeOrder returns a copy of order made valid for contract, or an error naming
+// what cannot be corrected. The input and its pointed-to fields are never modified.
+//
+// Prices are rounded to the contract's tick size in the direction that never worsens
+// the order: limits toward the passive side (down for buys, up for sells), stops away
+// from the market (up for buys, down for sells) and trail distances to the nearest
+// tick, at least one. An empty ContractID is filled in from contract. The gateway
+// publishes no price bands or size limits, so prices must only be positive and the
+// size at least one.
+func NormalizeOrder(order OrderRequest, contract Contract) (OrderRequest, error) {
+	out := order
+	out.LimitPrice = clonePtr(order.LimitPrice)
+	out.StopPrice = clonePtr(order.StopPrice)
+	out.TrailPrice = clonePtr(order.TrailPrice)
+	out.CustomTag = clonePtr(order.CustomTag)
+	out.LinkedOrderID = clonePtr(order.LinkedOrderID)
+	out.StopLossBracket = clonePtr(order.StopLossBracket)
+	out.TakeProfitBracket = clonePtr(order.TakeProfitBracket)
+
+	invalid := func(format string, v ...any) (OrderRequest, error) {
+		return order, fmt.Errorf("invalid order for %s: %s", contract.ID, fmt.Sprintf(format, v...))
+	}
+
+	switch {
+	case out.ContractID == "":
+		out.ContractID = contract.ID
+	case out.ContractID != contract.ID:
+		return invalid("contract ID %s does not match", out.ContractID)
+	}
+	if contract.TickSize <= 0 {
+		return invalid("contract has no tick size")
+	}
+	if out.Size < 1 {
+		return invalid("size %d must be at least 1", out.Size)
+	}
+	if out.Side != SideBuy && out.Side != SideSell {
+		return invalid("unknown side %d", out.Side)
+	}
+	if _, ok := OrderTypeName[out.Type]; !ok {
+		return invalid("unknown order type %d", out.Type)
+	}
+
+	limitMode, stopMode := RoundDown, RoundUp
+	if out.Side == SideSell {
+		limitMode, stopMode = RoundUp, RoundDown
+	}
+	prices := []struct {
+		name     string
+		price    *float64
+		mode     RoundMode
+		required bool
+	}{
+		{"limit", out.LimitPrice, limitMode, out.Type == OrderTypeLimit},
+		{"stop", out.StopPrice, stopMode, out.Type == OrderTypeStop},
+		{"trail", out.TrailPrice, RoundNearest, out.Type == OrderTypeTrailingStop},
+	}
+	for _, p := range prices {
+		if p.price == nil {
+			if p.required {
+				return invalid("%s order needs a %s price", OrderTypeName[out.Type], p.name)
+			}
+			continue
+		}
+		if *p.price <= 0 || math.IsNaN(*p.price) || math.IsInf(*p.price, 0) {
+			return invalid("%s price %v must be positive", p.name, *p.price)
+		}
+		*p.price = RoundToTick(*p.price, contract.TickSize, p.mode)
+		if *p.price <= 0 {
+			// The lowest valid price or trail distance is one tick
+			*p.price = contract.TickSize
+		}
+	}
+	return out, nil
+}
+
+// clonePtr returns a pointer to a copy of *p, or nil.
+func clonePtr[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
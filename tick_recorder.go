@@ -0,0 +1,186 @@
+package projectx
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+)
+
+// Tick kinds written to TickRecord.Kind
+const (
+	TickKindQuote = "quote"
+	TickKindTrade = "trade"
+	TickKindDepth = "depth"
+)
+
+// TickRecord is a single market data event as written by TickRecorder and
+// read back by BacktestFeed. Records are stored as newline-delimited JSON.
+type TickRecord struct {
+	Time       time.Time              `json:"time"`
+	Kind       string                 `json:"kind"`
+	ContractID string                 `json:"contractId"`
+	Data       map[string]interface{} `json:"data"`
+}
+
+// TickRecorder is a MarketDataHandler that appends every quote, trade and depth
+// update it receives to a file, rotating it by size and/or age.
+//
+// Rotated files are renamed to "<path>.<timestamp>" and a fresh file is opened
+// at path. Call Close when done so buffered records are flushed to disk.
+type TickRecorder struct {
+	mutex          sync.Mutex
+	path           string
+	file           *os.File
+	writer         *bufio.Writer
+	size           int64
+	openedAt       time.Time
+	rotateSize     int64
+	rotateInterval time.Duration
+}
+
+func NewTickRecorder(path string) (*TickRecorder, error) {
+	r := &TickRecorder{path: path}
+	if err := r.open(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// WithRotateSize rotates the file once it reaches the given size in bytes. Zero disables size rotation.
+func (r *TickRecorder) WithRotateSize(bytes int64) *TickRecorder {
+	r.mutex.Lock()
+	r.rotateSize = bytes
+	r.mutex.Unlock()
+	return r
+}
+
+// WithRotateInterval rotates the file once it has been open for the given duration. Zero disables time rotation.
+func (r *TickRecorder) WithRotateInterval(d time.Duration) *TickRecorder {
+	r.mutex.Lock()
+	r.rotateInterval = d
+	r.mutex.Unlock()
+	return r
+}
+
+func (r *TickRecorder) OnQuote(contractID string, data map[string]interface{}) {
+	r.record(TickKindQuote, contractID, data)
+}
+
+func (r *TickRecorder) OnTrade(contractID string, data map[string]interface{}) {
+	r.record(TickKindTrade, contractID, data)
+}
+
+func (r *TickRecorder) OnDepth(contractID string, data map[string]interface{}) {
+	r.record(TickKindDepth, contractID, data)
+}
+
+// Flush writes any buffered records to the underlying file.
+func (r *TickRecorder) Flush() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil {
+		return nil
+	}
+	if err := r.writer.Flush(); err != nil {
+		return err
+	}
+	return r.file.Sync()
+}
+
+// Close flushes buffered records and closes the file. Records received after Close are dropped.
+func (r *TickRecorder) Close() error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return r.close()
+}
+
+func (r *TickRecorder) record(kind, contractID string, data map[string]interface{}) {
+	line, err := json.Marshal(TickRecord{
+		Time:       time.Now().UTC(),
+		Kind:       kind,
+		ContractID: contractID,
+		Data:       data,
+	})
+	if err != nil {
+		log.Printf("Failed to encode %s tick: %v", kind, err)
+		return
+	}
+	line = append(line, '\n')
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if r.file == nil {
+		return
+	}
+
+	if r.shouldRotate(int64(len(line))) {
+		if err := r.rotate(); err != nil {
+			log.Printf("Failed to rotate tick file: %v", err)
+			return
+		}
+	}
+
+	n, err := r.writer.Write(line)
+	r.size += int64(n)
+	if err != nil {
+		log.Printf("Failed to write %s tick: %v", kind, err)
+	}
+}
+
+func (r *TickRecorder) shouldRotate(next int64) bool {
+	if r.size == 0 {
+		return false
+	}
+	if r.rotateSize > 0 && r.size+next > r.rotateSize {
+		return true
+	}
+	return r.rotateInterval > 0 && time.Since(r.openedAt) >= r.rotateInterval
+}
+
+func (r *TickRecorder) rotate() error {
+	if err := r.close(); err != nil {
+		return err
+	}
+	rotated := fmt.Sprintf("%s.%s", r.path, r.openedAt.UTC().Format("20060102T150405.000000000"))
+	if err := os.Rename(r.path, rotated); err != nil {
+		return err
+	}
+	return r.open()
+}
+
+func (r *TickRecorder) open() error {
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("failed to open tick file: %w", err)
+	}
+	info, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("failed to stat tick file: %w", err)
+	}
+	r.file = f
+	r.writer = bufio.NewWriter(f)
+	r.size = info.Size()
+	r.openedAt = time.Now()
+	return nil
+}
+
+func (r *TickRecorder) close() error {
+	if r.file == nil {
+		return nil
+	}
+	flushErr := r.writer.Flush()
+	closeErr := r.file.Close()
+	r.file = nil
+	r.writer = nil
+	if flushErr != nil {
+		return flushErr
+	}
+	return closeErr
+}
@@ -0,0 +1,75 @@
+package projectx
+
+import (
+	"math"
+	"testing"
+)
+
+func TestRoundToTick(t *testing.T) {
+	tests := []struct {
+		name              string
+		price, tick       float64
+		nearest, down, up float64
+	}{
+		{"exact tick", 4700.25, 0.25, 4700.25, 4700.25, 4700.25},
+		{"half tick", 4700.125, 0.25, 4700.25, 4700, 4700.25},
+		{"below half", 4700.1, 0.25, 4700, 4700, 4700.25},
+		{"above half", 4700.15, 0.25, 4700.25, 4700, 4700.25},
+		{"negative half tick", -1.125, 0.25, -1.25, -1.25, -1},
+		{"float noise above tick", 0.1 + 0.2, 0.1, 0.3, 0.3, 0.3},
+		{"float noise below tick", 0.3 - 1e-12, 0.1, 0.3, 0.3, 0.3},
+		{"decimal half tick", 0.35, 0.1, 0.4, 0.3, 0.4},
+		{"decimal just below half", 0.3499, 0.1, 0.3, 0.3, 0.4},
+		{"small tick", 1.08765, 0.0001, 1.0877, 1.0876, 1.0877},
+		{"fractional tick", 110 + 5.0/64, 1.0 / 32, 110 + 3.0/32, 110 + 2.0/32, 110 + 3.0/32},
+		{"non-positive tick", 4700.1, 0, 4700.1, 4700.1, 4700.1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			modes := []struct {
+				mode RoundMode
+				want float64
+			}{
+				{RoundNearest, tt.nearest},
+				{RoundDown, tt.down},
+				{RoundUp, tt.up},
+			}
+			for _, m := range modes {
+				if got := RoundToTick(tt.price, tt.tick, m.mode); math.Abs(got-m.want) > 1e-9 {
+					t.Errorf("RoundToTick(%v, %v, %d) = %v, want %v", tt.price, tt.tick, m.mode, got, m.want)
+				}
+			}
+		})
+	}
+}
+
+func TestTickDecimals(t *testing.T) {
+	tests := []struct {
+		tick     float64
+		decimals int
+		price    float64
+		format   string
+	}{
+		{1, 0, 4712, "4712"},
+		{5, 0, 38215, "38215"},
+		{0.5, 1, 101.5, "101.5"},
+		{0.25, 2, 4700.5, "4700.50"},
+		{0.1, 1, 0.1 + 0.2, "0.3"},
+		{0.01, 2, 78.4, "78.40"},
+		{0.005, 3, 1.235, "1.235"},
+		{0.0001, 4, 1.08765 - 0.00005, "1.0876"},
+		{0.00005, 5, 1.08765, "1.08765"},
+		{1.0 / 32, 5, 110 + 3.0/32, "110.09375"},
+		{1.0 / 64, 6, 110 + 1.0/64, "110.015625"},
+		{1.0 / 128, 7, 110 + 1.0/128, "110.0078125"},
+		{0, 2, 4700.1, "4700.10"},
+	}
+	for _, tt := range tests {
+		if got := TickDecimals(tt.tick); got != tt.decimals {
+			t.Errorf("TickDecimals(%v) = %d, want %d", tt.tick, got, tt.decimals)
+		}
+		if got := (Contract{TickSize: tt.tick}).FormatPrice(tt.price); got != tt.format {
+			t.Errorf("FormatPrice(%v) with tick %v = %q, want %q", tt.price, tt.tick, got, tt.format)
+		}
+	}
+}
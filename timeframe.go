@@ -0,0 +1,64 @@
+package projectx
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// timeUnitDuration is the length of one unit for the fixed-length time units.
+// Months vary in length and are deliberately absent.
+var timeUnitDuration = map[int]time.Duration{
+	TimeUnitSecond: time.Second,
+	TimeUnitMinute: time.Minute,
+	TimeUnitHour:   time.Hour,
+	TimeUnitDay:    24 * time.Hour,
+	TimeUnitWeek:   7 * 24 * time.Hour,
+}
+
+// BarPeriod returns the length of the bars requested. The second result is false
+// for month bars, which have no fixed length, and for unknown units.
+func (r HistoryRequest) BarPeriod() (time.Duration, bool) {
+	unit, ok := timeUnitDuration[r.Unit]
+	if !ok {
+		return 0, false
+	}
+	return time.Duration(r.UnitNumber) * unit, true
+}
+
+// SameTimeframe reports whether bars fetched with req have the same period as the
+// live bars built by m. Use it when backfilling history before streaming to catch
+// mismatches such as 5-minute history stitched onto 1-minute live bars.
+func SameTimeframe(req HistoryRequest, m *MarketDataManager) bool {
+	period, ok := req.BarPeriod()
+	return ok && period == m.BarPeriod()
+}
+
+// maxUnitNumber bounds UnitNumber per unit; larger bars are better requested in the next unit.
+var maxUnitNumber = map[int]int{
+	TimeUnitSecond: 3600,
+	TimeUnitMinute: 1440,
+	TimeUnitHour:   168,
+	TimeUnitDay:    365,
+	TimeUnitWeek:   52,
+	TimeUnitMonth:  12,
+}
+
+// Validate checks Unit against the TimeUnit constants and UnitNumber against a sensible
+// range for the unit, so mistakes fail locally with a clear error instead of as an
+// opaque gateway rejection.
+func (r HistoryRequest) Validate() error {
+	limit, ok := maxUnitNumber[r.Unit]
+	if !ok {
+		units := make([]string, 0, len(TimeUnitName))
+		for unit := TimeUnitSecond; unit <= TimeUnitMonth; unit++ {
+			units = append(units, fmt.Sprintf("%d (%s)", unit, TimeUnitName[unit]))
+		}
+		return fmt.Errorf("invalid history unit %d: valid units are %s", r.Unit, strings.Join(units, ", "))
+	}
+	if r.UnitNumber < 1 || r.UnitNumber > limit {
+		return fmt.Errorf("invalid history unit number %d for %s bars: must be between 1 and %d",
+			r.UnitNumber, TimeUnitName[r.Unit], limit)
+	}
+	return nil
+}
@@ -0,0 +1,163 @@
+package projectx
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TokenExpiry returns the expiry ("exp" claim) of a JWT. The signature is not verified.
+func TokenExpiry(token string) (time.Time, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return time.Time{}, fmt.Errorf("invalid JWT: expected 3 segments, got %d", len(parts))
+	}
+	payload, err := base64.RawURLEncoding.DecodeString(strings.TrimRight(parts[1], "="))
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid JWT payload: %v", err)
+	}
+	var claims struct {
+		Exp *json.Number `json:"exp"`
+	}
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return time.Time{}, fmt.Errorf("invalid JWT claims: %v", err)
+	}
+	if claims.Exp == nil {
+		return time.Time{}, fmt.Errorf("JWT has no exp claim")
+	}
+	exp, err := claims.Exp.Float64()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid JWT exp claim: %v", err)
+	}
+	return time.Unix(int64(exp), 0), nil
+}
+
+// TokenExpiry returns the expiry of the client's current token.
+func (c *Client) TokenExpiry() (time.Time, error) {
+	return TokenExpiry(c.GetToken())
+}
+
+// tokenExpired reports whether token expires within skew. Tokens whose expiry cannot be
+// determined are assumed valid and left to the server to reject.
+func tokenExpired(token string, skew time.Duration) bool {
+	exp, err := TokenExpiry(token)
+	if err != nil {
+		return false
+	}
+	return !time.Now().Add(skew).Before(exp)
+}
+
+// tokenRefreshRetry is how long KeepTokenFresh waits after a failed refresh.
+const tokenRefreshRetry = 30 * time.Second
+
+// OnTokenRefresh registers fn to receive the new token whenever the client
+// re-authenticates through the function given to WithAutoRetry, whether after a 401,
+// the WithTokenExpiryCheck pre-check, RefreshToken or KeepTokenFresh. Use it to hand
+// the token to the hub clients, whose connections would otherwise fail at the next
+// reconnect once the old token expires:
+//
+//	client.OnTokenRefresh(func(token string) {
+//		market.UpdateToken(token, true)
+//		user.UpdateToken(token, true)
+//	})
+//
+// Listeners run synchronously on the goroutine that refreshed the token.
+func (c *Client) OnTokenRefresh(fn func(token string)) *Client {
+	c.tokenListeners = append(c.tokenListeners, fn)
+	return c
+}
+
+// RefreshToken re-authenticates now through the function given to WithAutoRetry and
+// notifies the OnTokenRefresh listeners.
+func (c *Client) RefreshToken() error {
+	if c.authFunc == nil {
+		return errors.New("token refresh failed: no auth function, see WithAutoRetry")
+	}
+	return c.refreshToken()
+}
+
+// tokenRefresh is a refresh in progress; done is closed once err is set.
+type tokenRefresh struct {
+	done chan struct{}
+	err  error
+}
+
+// refreshToken calls the auth function and notifies listeners on success. Refreshes are
+// serialized: callers arriving while one is running wait for it and share its result,
+// so concurrent 401s cause a single login.
+func (c *Client) refreshToken() error {
+	return c.refreshTokenFrom("")
+}
+
+// refreshTokenFrom is refreshToken for a caller that found stale to be invalid. If the
+// token has already been replaced since, it returns at once without logging in again.
+func (c *Client) refreshTokenFrom(stale string) error {
+	c.tokenMutex.Lock()
+	if stale != "" && c.Token != stale {
+		c.tokenMutex.Unlock()
+		return nil
+	}
+	if r := c.refreshing; r != nil {
+		c.tokenMutex.Unlock()
+		<-r.done
+		return r.err
+	}
+	r := &tokenRefresh{done: make(chan struct{})}
+	c.refreshing = r
+	c.tokenMutex.Unlock()
+
+	r.err = c.authFunc()
+
+	c.tokenMutex.Lock()
+	c.refreshing = nil
+	token := c.Token
+	c.tokenMutex.Unlock()
+	close(r.done)
+
+	if r.err != nil {
+		return r.err
+	}
+	for _, fn := range c.tokenListeners {
+		fn(token)
+	}
+	return nil
+}
+
+// KeepTokenFresh refreshes the token the given time before it expires, again and again,
+// until ctx is done, so a long-lived hub connection fed through OnTokenRefresh never
+// holds an expired token even when no REST calls are made. Refreshes are at least 30
+// seconds apart; failures are logged and retried after that interval, as is a token
+// without a readable expiry. Run it in its own goroutine; it returns ctx.Err().
+func (c *Client) KeepTokenFresh(ctx context.Context, before time.Duration) error {
+	if c.authFunc == nil {
+		return errors.New("token refresh failed: no auth function, see WithAutoRetry")
+	}
+	for refreshed := false; ; {
+		wait := tokenRefreshRetry
+		if exp, err := c.TokenExpiry(); err == nil {
+			wait = time.Until(exp.Add(-before))
+		}
+		if refreshed && wait < tokenRefreshRetry {
+			// The new token is no fresher than before; avoid refreshing in a loop
+			wait = tokenRefreshRetry
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		case <-timer.C:
+		}
+
+		err := c.refreshToken()
+		refreshed = true
+		if err != nil {
+			c.logf("projectx: scheduled token refresh failed: %v", err)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package projectx
+
+import "sync"
+
+// DefaultTradeTapeSize is the number of trades kept per contract when NewTradeTape is
+// given a non-positive size.
+const DefaultTradeTapeSize = 1000
+
+// TradeTape is a MarketDataHandler that keeps the most recent trades of every contract
+// it receives in a fixed-size ring buffer, for time-and-sales displays. Quotes and depth
+// are ignored. It is safe for concurrent use; combine it with other handlers through
+// MultiHandler.
+type TradeTape struct {
+	mutex sync.RWMutex
+	size  int
+	tapes map[string]*tradeRing
+}
+
+// tradeRing is a ring buffer of trades; next is the slot the next trade is written to.
+type tradeRing struct {
+	trades []MarketTrade
+	next   int
+	full   bool
+}
+
+// NewTradeTape returns a tape keeping up to size trades per contract.
+func NewTradeTape(size int) *TradeTape {
+	if size <= 0 {
+		size = DefaultTradeTapeSize
+	}
+	return &TradeTape{size: size, tapes: make(map[string]*tradeRing)}
+}
+
+func (t *TradeTape) OnQuote(contractID string, data map[string]interface{}) {}
+
+func (t *TradeTape) OnDepth(contractID string, data map[string]interface{}) {}
+
+func (t *TradeTape) OnTrade(contractID string, data map[string]interface{}) {
+	trade := ParseMarketTrade(contractID, data)
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	ring, ok := t.tapes[contractID]
+	if !ok {
+		ring = &tradeRing{trades: make([]MarketTrade, t.size)}
+		t.tapes[contractID] = ring
+	}
+	ring.trades[ring.next] = trade
+	ring.next = (ring.next + 1) % len(ring.trades)
+	if ring.next == 0 {
+		ring.full = true
+	}
+}
+
+// RecentTrades returns up to n of the latest trades for contractID, oldest first. A
+// non-positive n returns every trade held.
+func (t *TradeTape) RecentTrades(contractID string, n int) []MarketTrade {
+	t.mutex.RLock()
+	defer t.mutex.RUnlock()
+	ring, ok := t.tapes[contractID]
+	if !ok {
+		return nil
+	}
+
+	count := ring.next
+	if ring.full {
+		count = len(ring.trades)
+	}
+	if n <= 0 || n > count {
+		n = count
+	}
+	out := make([]MarketTrade, n)
+	start := ring.next - n
+	if start < 0 {
+		start += len(ring.trades)
+	}
+	for i := range out {
+		out[i] = ring.trades[(start+i)%len(ring.trades)]
+	}
+	return out
+}
+
+// Clear drops the trades held for contractID.
+func (t *TradeTape) Clear(contractID string) {
+	t.mutex.Lock()
+	delete(t.tapes, contractID)
+	t.mutex.Unlock()
+}
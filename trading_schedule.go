@@ -0,0 +1,97 @@
+package projectx
+
+import (
+	"fmt"
+	"time"
+)
+
+// SessionWindow is one weekly trading window of a TradingSchedule. Open and Close are
+// wall-clock offsets from midnight of Day in the schedule's location; a Close beyond
+// 24h ends on a later day, e.g. Open 17h and Close 40h for 17:00 to 16:00 the next day.
+type SessionWindow struct {
+	Day   time.Weekday
+	Open  time.Duration
+	Close time.Duration
+}
+
+// TradingSchedule describes when a contract trades, as weekly windows in the
+// exchange's time zone. Daily maintenance breaks are the gaps between windows.
+//
+// The gateway exposes no trading hours, so schedules are supplied by the caller, see
+// WithTradingSchedules. Holidays and early closes are not modelled.
+type TradingSchedule struct {
+	Location *time.Location // Exchange time zone; nil means UTC
+	Windows  []SessionWindow
+}
+
+// CMEEquityIndexSchedule returns the regular CME Globex hours of equity index futures
+// such as ES and NQ: Sunday to Thursday from 17:00 to 16:00 the next day, Chicago time,
+// with a one-hour daily maintenance break.
+func CMEEquityIndexSchedule() *TradingSchedule {
+	chicago, err := time.LoadLocation("America/Chicago")
+	if err != nil {
+		chicago = time.FixedZone("CST", -6*60*60)
+	}
+	s := &TradingSchedule{Location: chicago}
+	for day := time.Sunday; day <= time.Thursday; day++ {
+		s.Windows = append(s.Windows, SessionWindow{Day: day, Open: 17 * time.Hour, Close: 40 * time.Hour})
+	}
+	return s
+}
+
+// IsInSession reports whether the contract trades at t.
+func (s *TradingSchedule) IsInSession(t time.Time) bool {
+	loc := s.Location
+	if loc == nil {
+		loc = time.UTC
+	}
+	local := t.In(loc)
+	for _, w := range s.Windows {
+		// The window may have opened up to a few days before t
+		span := int(w.Close/(24*time.Hour)) + 1
+		for back := 0; back <= span; back++ {
+			day := time.Date(local.Year(), local.Month(), local.Day()-back, 0, 0, 0, 0, loc)
+			if day.Weekday() != w.Day {
+				continue
+			}
+			open := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, int(w.Open), loc)
+			close := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, int(w.Close), loc)
+			if !t.Before(open) && t.Before(close) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Calendar returns IsInSession as a SessionCalendar, e.g. for DetectGaps.
+func (s *TradingSchedule) Calendar() SessionCalendar {
+	return s.IsInSession
+}
+
+// TradingSchedules maps a contract ID or symbol ID to its schedule, keyed like
+// FeeSchedule, so one entry covers every expiry of a product.
+type TradingSchedules map[string]*TradingSchedule
+
+// WithTradingSchedules sets the schedules returned by GetContractSchedule.
+func WithTradingSchedules(schedules TradingSchedules) ClientOption {
+	return func(c *Client) {
+		c.schedules = schedules
+	}
+}
+
+// GetContractSchedule returns the trading schedule of the contract from the schedules
+// set with WithTradingSchedules, matching the contract ID first and then its symbol ID.
+// The gateway has no trading hours endpoint, so no request is made. It returns an
+// error wrapping ErrNotFound when no schedule is configured for the contract.
+func (c *Client) GetContractSchedule(contractId string) (*TradingSchedule, error) {
+	if s, ok := c.schedules[contractId]; ok {
+		return s, nil
+	}
+	if parts, err := ParseContractID(contractId); err == nil {
+		if s, ok := c.schedules[parts.SymbolID()]; ok {
+			return s, nil
+		}
+	}
+	return nil, fmt.Errorf("trading schedule for %s: %w", contractId, ErrNotFound)
+}
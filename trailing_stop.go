@@ -0,0 +1,182 @@
+package projectx
+
+import (
+	"fmt"
+	"sync"
+)
+
+// TrailingStop describes a protective stop order managed by a TrailingStopManager.
+type TrailingStop struct {
+	AccountID  int
+	ContractID string
+	OrderID    int     // Working stop order protecting the position
+	Side       Side    // Side of the position: SideBuy for long, SideSell for short
+	TickSize   float64 // Contract tick size
+	TrailTicks int     // Distance kept between the best price and the stop
+	Stop       float64 // Current stop price
+}
+
+// NewTrailingStop describes the trailing of stop order orderID, currently at stop,
+// protecting position, trailTicks ticks behind the best price.
+func NewTrailingStop(position OpenPosition, contract Contract, orderID int, stop float64, trailTicks int) TrailingStop {
+	return TrailingStop{
+		AccountID:  position.AccountID,
+		ContractID: position.ContractID,
+		OrderID:    orderID,
+		Side:       position.Side(),
+		TickSize:   contract.TickSize,
+		TrailTicks: trailTicks,
+		Stop:       stop,
+	}
+}
+
+// trailingState is a tracked stop with a flag set while a modify is in flight.
+type trailingState struct {
+	TrailingStop
+	pending bool
+}
+
+// TrailingStopManager ratchets stop orders client-side as price moves in the
+// position's favour, for brokers whose native trailing stops are unreliable. It never
+// moves a stop against the position.
+//
+// It is a MarketDataHandler: feed it from the SignalRClient subscribed to the
+// contracts, through a MultiHandler when other handlers share the connection. Trade
+// prices and the quote's last price drive the trail. Feed OnOrderEvent from the
+// UserHubClient callback so stops are forgotten once they fill or are cancelled.
+type TrailingStopManager struct {
+	mutex   sync.Mutex
+	client  TradingAPI
+	stops   map[int]*trailingState // Tracked stops by order ID
+	minStep int                    // Minimum improvement in ticks before modifying
+	onError func(stop TrailingStop, err error)
+}
+
+// NewTrailingStopManager creates a manager that modifies stops through client.
+// onError, which may be nil, receives failed modifications; the stop is retried on
+// the next favourable price.
+func NewTrailingStopManager(client TradingAPI, onError func(stop TrailingStop, err error)) *TrailingStopManager {
+	return &TrailingStopManager{
+		client:  client,
+		stops:   make(map[int]*trailingState),
+		minStep: 1,
+		onError: onError,
+	}
+}
+
+// WithMinStepTicks sets how many ticks the stop must improve by before it is modified,
+// to avoid a modify on every tick. The default is 1.
+func (m *TrailingStopManager) WithMinStepTicks(ticks int) *TrailingStopManager {
+	if ticks < 1 {
+		ticks = 1
+	}
+	m.mutex.Lock()
+	m.minStep = ticks
+	m.mutex.Unlock()
+	return m
+}
+
+// Track starts trailing the stop. It returns an error if the description is unusable.
+func (m *TrailingStopManager) Track(stop TrailingStop) error {
+	if stop.TickSize <= 0 || stop.TrailTicks <= 0 {
+		return fmt.Errorf("invalid trailing stop for order %d: tick size and trail ticks must be positive", stop.OrderID)
+	}
+	m.mutex.Lock()
+	m.stops[stop.OrderID] = &trailingState{TrailingStop: stop}
+	m.mutex.Unlock()
+	return nil
+}
+
+// Untrack stops trailing the stop order with the given ID.
+func (m *TrailingStopManager) Untrack(orderID int) {
+	m.mutex.Lock()
+	delete(m.stops, orderID)
+	m.mutex.Unlock()
+}
+
+// Stop returns the current state of the tracked stop order.
+func (m *TrailingStopManager) Stop(orderID int) (TrailingStop, bool) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+	st, ok := m.stops[orderID]
+	if !ok {
+		return TrailingStop{}, false
+	}
+	return st.TrailingStop, true
+}
+
+// OnOrderEvent forgets stops that filled, were cancelled or were rejected. It has the
+// OrderEventCallback signature.
+func (m *TrailingStopManager) OnOrderEvent(event OrderEvent) {
+	switch event.Type {
+	case OrderFilled, OrderCancelled, OrderRejected:
+		m.Untrack(event.OrderID)
+	}
+}
+
+func (m *TrailingStopManager) OnQuote(contractID string, data map[string]interface{}) {
+	if last := ParseQuote(contractID, data).Last; last > 0 {
+		m.onPrice(contractID, last)
+	}
+}
+
+func (m *TrailingStopManager) OnTrade(contractID string, data map[string]interface{}) {
+	if price := ParseMarketTrade(contractID, data).Price; price > 0 {
+		m.onPrice(contractID, price)
+	}
+}
+
+func (m *TrailingStopManager) OnDepth(contractID string, data map[string]interface{}) {}
+
+// onPrice moves every stop on the contract that price improves by at least the minimum
+// step. Modifies are sent outside the lock, one at a time per stop.
+func (m *TrailingStopManager) onPrice(contractID string, price float64) {
+	type update struct {
+		state *trailingState
+		stop  TrailingStop
+	}
+	var updates []update
+
+	m.mutex.Lock()
+	for _, st := range m.stops {
+		if st.ContractID != contractID || st.pending {
+			continue
+		}
+		if next, ok := st.ratchet(price, m.minStep); ok {
+			st.pending = true
+			stop := st.TrailingStop
+			stop.Stop = next
+			updates = append(updates, update{st, stop})
+		}
+	}
+	m.mutex.Unlock()
+
+	for _, u := range updates {
+		next := u.stop.Stop
+		err := m.client.ModifyOrder(u.stop.AccountID, u.stop.OrderID, nil, nil, &next, nil)
+
+		m.mutex.Lock()
+		u.state.pending = false
+		if err == nil {
+			u.state.Stop = next
+		}
+		m.mutex.Unlock()
+
+		if err != nil && m.onError != nil {
+			m.onError(u.stop, err)
+		}
+	}
+}
+
+// ratchet returns the new stop for price if it improves the current stop by at least
+// minStep ticks.
+func (s *trailingState) ratchet(price float64, minStep int) (float64, bool) {
+	distance := float64(s.TrailTicks) * s.TickSize
+	step := float64(minStep) * s.TickSize
+	if s.Side == SideSell {
+		next := RoundToTick(price+distance, s.TickSize, RoundUp)
+		return next, next <= s.Stop-step+s.TickSize/2
+	}
+	next := RoundToTick(price-distance, s.TickSize, RoundDown)
+	return next, next >= s.Stop+step-s.TickSize/2
+}
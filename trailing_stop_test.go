@@ -0,0 +1,80 @@
+package projectx_test
+
+import (
+	"math"
+	"testing"
+
+	"github.com/optionsvamp/projectx"
+	"github.com/optionsvamp/projectx/projectxtest"
+)
+
+func TestTrailingStopRatchet(t *testing.T) {
+	const es = "CON.F.US.EP.H24"
+	tests := []struct {
+		name   string
+		side   projectx.Side
+		stop   float64
+		prices []float64
+		want   []float64 // Stops sent, in order
+	}{
+		{
+			name:   "long",
+			side:   projectx.SideBuy,
+			stop:   99,
+			prices: []float64{100, 100.25, 100.5, 100.1, 99, 101.3, 101.6, 101.75},
+			want:   []float64{99.5, 100.25, 100.75},
+		},
+		{
+			name:   "short",
+			side:   projectx.SideSell,
+			stop:   101,
+			prices: []float64{100, 99.75, 99.5, 100.2, 98.7, 98.6, 98.25},
+			want:   []float64{100.5, 99.75, 99.25},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fake := projectxtest.NewFakeClient()
+			m := projectx.NewTrailingStopManager(fake, nil).WithMinStepTicks(2)
+			stop := projectx.TrailingStop{AccountID: 7, ContractID: es, OrderID: 42, Side: tt.side, TickSize: 0.25, TrailTicks: 4, Stop: tt.stop}
+			if err := m.Track(stop); err != nil {
+				t.Fatal(err)
+			}
+
+			for _, price := range tt.prices {
+				m.OnTrade(es, map[string]interface{}{"price": price, "size": 1.0})
+			}
+			// Other contracts do not move the stop
+			m.OnTrade("CON.F.US.ENQ.H24", map[string]interface{}{"price": 1.0, "size": 1.0})
+
+			var sent []float64
+			for _, call := range fake.ModifiedOrders {
+				if call.OrderID != 42 || call.StopPrice == nil || call.LimitPrice != nil || call.Size != nil {
+					t.Fatalf("unexpected modify %+v", call)
+				}
+				sent = append(sent, *call.StopPrice)
+			}
+			if len(sent) != len(tt.want) {
+				t.Fatalf("sent stops %v, want %v", sent, tt.want)
+			}
+			prev := tt.stop
+			for i, s := range sent {
+				if s != tt.want[i] {
+					t.Errorf("stop %d = %v, want %v", i, s, tt.want[i])
+				}
+				moved := (s - prev) / 0.25
+				if tt.side == projectx.SideSell {
+					moved = -moved
+				}
+				if moved < 2 || moved != math.Trunc(moved) {
+					t.Errorf("stop %d moved %v ticks from %v, want a favourable whole number of at least 2", i, moved, prev)
+				}
+				prev = s
+			}
+
+			if got, _ := m.Stop(42); got.Stop != tt.want[len(tt.want)-1] {
+				t.Errorf("tracked stop %v, want %v", got.Stop, tt.want[len(tt.want)-1])
+			}
+		})
+	}
+}
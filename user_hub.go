@@ -0,0 +1,286 @@
+package projectx
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+
+	"github.com/philippseith/signalr"
+)
+
+// DefaultUserHubURL is the user hub used unless WithHubURL is given.
+const DefaultUserHubURL = "https://rtc.thefuturesdesk.projectx.com/hubs/user"
+
+// UserHubClient manages the WebSocket connection to the user hub using SignalR.
+// It subscribes to order updates per account and reports them as order lifecycle events.
+type UserHubClient struct {
+	client       signalr.Client      // The underlying SignalR client
+	mutex        sync.RWMutex        // Protects access to shared state
+	accounts     map[int]bool        // Accounts subscribed to order updates
+	tracker      *OrderTracker       // Turns order updates into lifecycle events
+	isConnected  bool                // Current connection state
+	ctx          context.Context     // Context for cancellation
+	cancel       context.CancelFunc  // Function to cancel the context
+	config       signalRConfig       // Connection settings used for every (re)connect
+	token        string              // JWT used for the next (re)connect
+	connCancel   context.CancelFunc  // Cancels the current connection, forcing a reconnect
+	dialed       bool                // Set once the first connection has been dialed
+	onGiveUp     func(lastErr error) // Called once reconnection is abandoned
+	gaveUp       bool                // Set once the reconnect policy has given up
+	reconnecting bool                // Set while the reconnect loop is running
+}
+
+// NewUserHubClient creates a SignalR client for the user hub that delivers order lifecycle
+// events to onOrderEvent. The connection is made when Start is called.
+func NewUserHubClient(jwtToken string, onOrderEvent OrderEventCallback, opts ...SignalROption) (*UserHubClient, error) {
+	// Collect connection settings
+	cfg := newSignalRConfig(DefaultUserHubURL, opts)
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+
+	// Create a cancellable context for the client
+	ctx, cancel := context.WithCancel(context.Background())
+
+	client := &UserHubClient{
+		accounts: make(map[int]bool),
+		tracker:  NewOrderTracker(onOrderEvent),
+		ctx:      ctx,
+		cancel:   cancel,
+		config:   cfg,
+		token:    jwtToken,
+	}
+
+	// Register this instance as the message receiver; the connector dials with the current
+	// token and retries lost connections according to the reconnect policy
+	c, err := newHubClient(ctx, cfg, client.connect, client)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+
+	client.client = c
+	return client, nil
+}
+
+// connect dials the first connection directly. Afterwards every call is a reconnect and
+// is retried according to the reconnect policy.
+func (c *UserHubClient) connect() (signalr.Connection, error) {
+	c.mutex.Lock()
+	dialed := c.dialed
+	c.dialed = true
+	c.mutex.Unlock()
+
+	if !dialed {
+		return c.dial()
+	}
+	return c.reconnect()
+}
+
+// reconnect dials the hub until it succeeds, the client is stopped, or the policy gives up.
+func (c *UserHubClient) reconnect() (signalr.Connection, error) {
+	c.mutex.Lock()
+	policy := c.config.reconnect
+	if c.gaveUp {
+		c.mutex.Unlock()
+		return nil, fmt.Errorf("reconnection abandoned")
+	}
+	// Cleared by OnConnected once the new connection completes its handshake
+	c.reconnecting = true
+	c.mutex.Unlock()
+
+	conn, err := redial(c.ctx, policy, "User hub", c.dial)
+	if err == nil {
+		return conn, nil
+	}
+	if c.ctx.Err() != nil {
+		c.mutex.Lock()
+		c.reconnecting = false
+		c.mutex.Unlock()
+		return nil, c.ctx.Err()
+	}
+	c.giveUp(err)
+	return nil, fmt.Errorf("reconnection failed after %d attempts: %w", policy.MaxAttempts, err)
+}
+
+// giveUp marks reconnection as abandoned, stops the client and fires the callback exactly once.
+func (c *UserHubClient) giveUp(lastErr error) {
+	c.mutex.Lock()
+	if c.gaveUp {
+		c.mutex.Unlock()
+		return
+	}
+	c.gaveUp = true
+	c.reconnecting = false
+	fn := c.onGiveUp
+	c.mutex.Unlock()
+
+	log.Printf("User hub giving up on reconnection: %v", lastErr)
+	c.cancel()
+	if fn != nil {
+		fn(lastErr)
+	}
+}
+
+// OnReconnectFailed registers a callback fired once when the reconnect policy gives up,
+// see SignalRClient.OnReconnectFailed. Order events stop until the client is replaced.
+func (c *UserHubClient) OnReconnectFailed(fn func(lastErr error)) {
+	c.mutex.Lock()
+	c.onGiveUp = fn
+	c.mutex.Unlock()
+}
+
+// IsReconnecting reports whether the client is re-establishing a lost connection.
+func (c *UserHubClient) IsReconnecting() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.reconnecting
+}
+
+// dial creates a new HTTP connection to the user hub authenticated with the current token.
+func (c *UserHubClient) dial() (signalr.Connection, error) {
+	c.mutex.RLock()
+	cfg := c.config
+	token := c.token
+	c.mutex.RUnlock()
+
+	conn, connCancel, err := dialHub(c.ctx, cfg, token)
+	if err != nil {
+		return nil, err
+	}
+
+	c.mutex.Lock()
+	c.connCancel = connCancel
+	c.mutex.Unlock()
+	return conn, nil
+}
+
+// UpdateToken replaces the JWT used to authenticate with the hub.
+// Pass reconnect=true to drop the current connection and reconnect with the new token immediately.
+func (c *UserHubClient) UpdateToken(jwtToken string, reconnect bool) {
+	c.mutex.Lock()
+	c.token = jwtToken
+	connCancel := c.connCancel
+	c.mutex.Unlock()
+
+	if reconnect && connCancel != nil {
+		connCancel()
+	}
+}
+
+// OnConnected is called when the SignalR connection is established.
+// It resubscribes to order updates for all previously subscribed accounts.
+func (c *UserHubClient) OnConnected(connectionID string) {
+	c.mutex.Lock()
+	c.isConnected = true
+	c.reconnecting = false
+	accounts := make([]int, 0, len(c.accounts))
+	for accountID := range c.accounts {
+		accounts = append(accounts, accountID)
+	}
+	c.mutex.Unlock()
+	log.Printf("User hub connected with ID: %s", connectionID)
+
+	for _, accountID := range accounts {
+		if err := c.SubscribeOrders(accountID); err != nil {
+			log.Printf("Failed to resubscribe to orders for account %d: %v", accountID, err)
+		}
+	}
+}
+
+// OnDisconnected is called when the SignalR connection is lost.
+func (c *UserHubClient) OnDisconnected(connectionID string) {
+	c.mutex.Lock()
+	c.isConnected = false
+	c.mutex.Unlock()
+	log.Printf("User hub disconnected")
+}
+
+// OnGatewayUserOrder handles incoming order updates from the SignalR hub.
+// The order may arrive bare or wrapped as {"action": ..., "data": {...}}.
+func (c *UserHubClient) OnGatewayUserOrder(data map[string]interface{}) {
+	if inner, ok := data["data"].(map[string]interface{}); ok {
+		data = inner
+	}
+	order, err := decodeOrderInfo(data)
+	if err != nil {
+		log.Printf("Failed to decode order update: %v", err)
+		return
+	}
+	c.tracker.Update(order)
+}
+
+// decodeOrderInfo converts a hub payload into an OrderInfo.
+func decodeOrderInfo(data map[string]interface{}) (OrderInfo, error) {
+	var order OrderInfo
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return order, err
+	}
+	err = json.Unmarshal(raw, &order)
+	return order, err
+}
+
+// Start initiates the SignalR connection.
+func (c *UserHubClient) Start() error {
+	c.client.Start()
+	return nil
+}
+
+// Stop shuts down the SignalR connection.
+func (c *UserHubClient) Stop() error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	c.cancel() // Cancel the context to stop all operations
+	c.isConnected = false
+	c.client.Stop()
+	return nil
+}
+
+// SubscribeOrders subscribes to order updates for the given account.
+// Subscriptions are restored automatically after a reconnect.
+func (c *UserHubClient) SubscribeOrders(accountID int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.isConnected {
+		return fmt.Errorf("not connected to SignalR hub")
+	}
+
+	if err := <-c.client.Send("SubscribeOrders", accountID); err != nil {
+		return fmt.Errorf("failed to subscribe to orders: %v", err)
+	}
+	c.accounts[accountID] = true
+	return nil
+}
+
+// UnsubscribeOrders stops order updates for the given account.
+func (c *UserHubClient) UnsubscribeOrders(accountID int) error {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if !c.isConnected {
+		return fmt.Errorf("not connected to SignalR hub")
+	}
+
+	if err := <-c.client.Send("UnsubscribeOrders", accountID); err != nil {
+		return fmt.Errorf("failed to unsubscribe from orders: %v", err)
+	}
+	delete(c.accounts, accountID)
+	return nil
+}
+
+// IsConnected returns the current connection state.
+func (c *UserHubClient) IsConnected() bool {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+	return c.isConnected
+}
+
+// Orders returns the order tracker, e.g. to look up the latest snapshot of a working order.
+func (c *UserHubClient) Orders() *OrderTracker {
+	return c.tracker
+}
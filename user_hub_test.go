@@ -0,0 +1,51 @@
+package projectx
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestUserHubReconnectGivesUp(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	policy := ReconnectPolicy{
+		MaxAttempts:  2,
+		InitialDelay: time.Millisecond,
+		Jitter:       func(time.Duration) time.Duration { return 0 },
+	}
+	c := &UserHubClient{
+		accounts: make(map[int]bool),
+		ctx:      ctx,
+		cancel:   cancel,
+		// An unparsable hub URL makes every dial fail
+		config: newSignalRConfig("://no-scheme", []SignalROption{WithReconnectPolicy(policy)}),
+		dialed: true, // The first connection was made and then lost
+	}
+
+	var gaveUp []error
+	c.OnReconnectFailed(func(lastErr error) { gaveUp = append(gaveUp, lastErr) })
+
+	_, err := c.connect()
+	if err == nil || !strings.Contains(err.Error(), "after 2 attempts") {
+		t.Fatalf("reconnect returned %v, want failure after 2 attempts", err)
+	}
+	if len(gaveUp) != 1 || gaveUp[0] == nil {
+		t.Errorf("give-up callback got %v, want one error", gaveUp)
+	}
+	if ctx.Err() == nil {
+		t.Error("client still running after giving up")
+	}
+	if c.IsReconnecting() {
+		t.Error("still reconnecting after giving up")
+	}
+
+	// Further reconnects are refused without another callback
+	if _, err := c.connect(); err == nil {
+		t.Error("reconnect after giving up succeeded")
+	}
+	if len(gaveUp) != 1 {
+		t.Errorf("give-up callback fired %d times, want 1", len(gaveUp))
+	}
+}